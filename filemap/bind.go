@@ -0,0 +1,349 @@
+/*
+NAME
+  bind.go - binding of maps to structs using struct tags.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  bind.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned by Unmarshal and Marshal.
+var (
+	ErrNotStruct       = errors.New("target is not a pointer to a struct")
+	ErrInvalidValue    = errors.New("value is not one of the permitted values")
+	ErrUnsupportedType = errors.New("unsupported field type")
+)
+
+// tagName is the name of struct tags used by Unmarshal and Marshal.
+const tagName = "filemap"
+
+// defaultDelim separates the elements of slice values.
+const defaultDelim = ","
+
+// KeyError describes a failure to decode the value of a key.
+type KeyError struct {
+	Key   string // The key whose value failed to decode.
+	Value string // The offending value.
+	Err   error  // The underlying error.
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("filemap: key %q, value %q: %v", e.Key, e.Value, e.Err)
+}
+
+func (e *KeyError) Unwrap() error { return e.Err }
+
+// KeyErrors is a list of errors for individual keys, returned by
+// Unmarshal so that all bad values are reported at once.
+type KeyErrors []*KeyError
+
+func (e KeyErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the individual errors, so that errors.Is and errors.As
+// match any of them.
+func (e KeyErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// fieldTag holds the options of a field's struct tag. See Unmarshal.
+type fieldTag struct {
+	key        string
+	def        string
+	hasDefault bool
+	enum       []string
+	delim      string
+}
+
+// parseTag parses the struct tag of field f, returning false if the field
+// is to be skipped.
+func parseTag(f reflect.StructField) (fieldTag, bool) {
+	if f.PkgPath != "" {
+		return fieldTag{}, false // Unexported.
+	}
+	parts := strings.Split(f.Tag.Get(tagName), ",")
+	ft := fieldTag{key: parts[0], delim: defaultDelim}
+	if ft.key == "-" {
+		return ft, false
+	}
+	if ft.key == "" {
+		ft.key = f.Name
+	}
+	for _, opt := range parts[1:] {
+		name, val, _ := strings.Cut(opt, "=")
+		switch name {
+		case "default":
+			ft.def, ft.hasDefault = val, true
+		case "enum":
+			ft.enum = strings.Split(val, "|")
+		case "delim":
+			ft.delim = val
+		}
+	}
+	if ft.hasDefault && f.Type.Kind() == reflect.Slice {
+		ft.def = strings.ReplaceAll(ft.def, "|", ft.delim)
+	}
+	return ft, true
+}
+
+// Unmarshal decodes the map fm into the struct pointed to by v, according
+// to the struct tags of its fields, which have the form:
+//
+//	filemap:"key,default=value,enum=a|b|c,delim=;"
+//
+// The key defaults to the field name and a key of "-" skips the field.
+// Options are separated by commas, so enumerated values are separated by
+// "|", as are the elements of the default of a slice.
+//
+// Supported field types are strings, ints, uints, floats, bools,
+// time.Duration and slices of these, with slice elements separated by the
+// delimiter, which is a comma by default. Integers are decimal, even with
+// leading zeros, unless prefixed with 0x for hexadecimal. Fields whose key is absent take
+// their default value, if any, and are otherwise left unchanged. If enum
+// is given, the value, or each element of a slice, must be one of the
+// enumerated values, as checked by IsValid. For example:
+//
+//	type Config struct {
+//		Period time.Duration `filemap:"mp,default=1m"`
+//		Mode   string        `filemap:"mo,default=normal,enum=normal|paused"`
+//		Pins   []string      `filemap:"ip"`
+//	}
+//
+// All values that fail to decode or validate are reported as KeyErrors.
+func Unmarshal(fm map[string]string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	var errs KeyErrors
+	for i := 0; i < rt.NumField(); i++ {
+		ft, ok := parseTag(rt.Field(i))
+		if !ok {
+			continue
+		}
+		raw, ok := fm[ft.key]
+		if !ok {
+			if !ft.hasDefault {
+				continue
+			}
+			raw = ft.def
+		}
+
+		fv := rv.Field(i)
+		err := ft.validate(raw, fv.Kind() == reflect.Slice)
+		if err == nil {
+			err = decode(fv, raw, ft.delim)
+		}
+		if err != nil {
+			errs = append(errs, &KeyError{Key: ft.key, Value: raw, Err: err})
+		}
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// validate checks raw against the enumerated values, if any.
+func (ft fieldTag) validate(raw string, slice bool) error {
+	if ft.enum == nil {
+		return nil
+	}
+	delim := ""
+	if slice {
+		if raw == "" {
+			return nil
+		}
+		delim = ft.delim
+	}
+	if !IsValid(map[string]string{ft.key: raw}, ft.key, ft.enum, delim) {
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// intBase returns the digits of the integer s and their base. Integers are
+// decimal, so that leading zeros are ignored, unless prefixed with 0x or 0X.
+// A sign must precede the prefix, so a sign following it is an error.
+func intBase(s string) (string, int, error) {
+	var sign string
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		sign, s = s[:1], s[1:]
+	}
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		if s[2] == '+' || s[2] == '-' {
+			return "", 0, fmt.Errorf("%w: sign follows base prefix", strconv.ErrSyntax)
+		}
+		return sign + s[2:], 16, nil
+	}
+	return sign + s, 10, nil
+}
+
+// decode sets v from its string representation s.
+func decode(v reflect.Value, s, delim string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s, base, err := intBase(s)
+		if err != nil {
+			return err
+		}
+		n, err := strconv.ParseInt(s, base, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s, base, err := intBase(s)
+		if err != nil {
+			return err
+		}
+		n, err := strconv.ParseUint(s, base, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Slice {
+			return ErrUnsupportedType
+		}
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, delim)
+		}
+		sl := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			err := decode(sl.Index(i), p, delim)
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(sl)
+	default:
+		return ErrUnsupportedType
+	}
+	return nil
+}
+
+// Marshal encodes the struct, or pointer to struct, v as a map, using
+// the same struct tags as Unmarshal.
+func Marshal(v interface{}) (map[string]string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+	rt := rv.Type()
+
+	fm := map[string]string{}
+	for i := 0; i < rt.NumField(); i++ {
+		ft, ok := parseTag(rt.Field(i))
+		if !ok {
+			continue
+		}
+		s, err := encode(rv.Field(i), ft.delim)
+		if err != nil {
+			return nil, &KeyError{Key: ft.key, Err: err}
+		}
+		fm[ft.key] = s
+	}
+	return fm, nil
+}
+
+// encode returns the string representation of v.
+func encode(v reflect.Value, delim string) (string, error) {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Slice {
+			return "", ErrUnsupportedType
+		}
+		parts := make([]string, v.Len())
+		for i := range parts {
+			s, err := encode(v.Index(i), delim)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, delim), nil
+	}
+	return "", ErrUnsupportedType
+}
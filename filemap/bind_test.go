@@ -0,0 +1,211 @@
+/*
+NAME
+  bind_test.go - tests for bind.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  bind_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name    string        `filemap:"nm"`
+	Period  time.Duration `filemap:"mp,default=1m"`
+	Retries int           `filemap:"rt,default=3"`
+	Gain    float64       `filemap:"gn"`
+	Enabled bool          `filemap:"en,default=true"`
+	Mode    string        `filemap:"mo,default=normal,enum=normal|paused"`
+	Pins    []string      `filemap:"ip,enum=A0|A1|D0"`
+	Levels  []uint8       `filemap:"lv,delim=;,default=1|2"`
+	Other   string
+	Skipped string `filemap:"-"`
+	private string
+}
+
+// TestUnmarshal tests decoding of values and defaults.
+func TestUnmarshal(t *testing.T) {
+	fm := map[string]string{
+		"nm":      "rig",
+		"rt":      "0x10",
+		"gn":      "1.5",
+		"ip":      "A0,D0",
+		"Other":   "other",
+		"Skipped": "skipped",
+		"-":       "skipped",
+		"private": "private",
+	}
+	var got testConfig
+	err := Unmarshal(fm, &got)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := testConfig{
+		Name:    "rig",
+		Period:  time.Minute,
+		Retries: 16,
+		Gain:    1.5,
+		Enabled: true,
+		Mode:    "normal",
+		Pins:    []string{"A0", "D0"},
+		Levels:  []uint8{1, 2},
+		Other:   "other",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// TestUnmarshalInts tests that integers are decimal unless explicitly
+// hexadecimal.
+func TestUnmarshalInts(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "10", want: 10},
+		{in: "010", want: 10},
+		{in: "09", want: 9},
+		{in: "-007", want: -7},
+		{in: "+5", want: 5},
+		{in: "0x1F", want: 31},
+		{in: "0X10", want: 16},
+		{in: "-0x10", want: -16},
+		{in: "0b11", wantErr: true},
+		{in: "0o17", wantErr: true},
+		{in: "0x", wantErr: true},
+		{in: "0x-5", wantErr: true},
+		{in: "0x+5", wantErr: true},
+		{in: "1_000", wantErr: true},
+	}
+	for _, test := range tests {
+		var got testConfig
+		err := Unmarshal(map[string]string{"rt": test.in}, &got)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%q: unexpected error: %v", test.in, err)
+			continue
+		}
+		if !test.wantErr && got.Retries != test.want {
+			t.Errorf("%q: got %d, want %d", test.in, got.Retries, test.want)
+		}
+	}
+
+	var got testConfig
+	err := Unmarshal(map[string]string{"lv": "08;0x0a"}, &got)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if want := []uint8{8, 10}; !reflect.DeepEqual(got.Levels, want) {
+		t.Errorf("got %v, want %v", got.Levels, want)
+	}
+}
+
+// TestUnmarshalErrors tests that all bad values are reported by key.
+func TestUnmarshalErrors(t *testing.T) {
+	fm := map[string]string{
+		"mp": "soon",
+		"rt": "three",
+		"en": "yes",
+		"mo": "stopped",
+		"ip": "A0,B7",
+		"lv": "1;300",
+		"nm": "ok",
+	}
+	cfg := testConfig{Gain: 2}
+	err := Unmarshal(fm, &cfg)
+	var errs KeyErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got error %v, want KeyErrors", err)
+	}
+
+	var keys []string
+	for _, e := range errs {
+		keys = append(keys, e.Key)
+	}
+	wantKeys := []string{"mp", "rt", "en", "mo", "ip", "lv"}
+	if !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("got errors for keys %v, want %v", keys, wantKeys)
+	}
+	if !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected error to match ErrInvalidValue")
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Errorf("expected error to match *strconv.NumError")
+	}
+
+	// Good values are still decoded and absent keys left unchanged.
+	if cfg.Name != "ok" || cfg.Gain != 2 {
+		t.Errorf("got Name %q and Gain %v, want ok and 2", cfg.Name, cfg.Gain)
+	}
+
+	if err := Unmarshal(fm, cfg); err != ErrNotStruct {
+		t.Errorf("got error %v for non-pointer, want %v", err, ErrNotStruct)
+	}
+}
+
+// TestMarshal tests that Marshal is the inverse of Unmarshal.
+func TestMarshal(t *testing.T) {
+	cfg := testConfig{
+		Name:    "rig",
+		Period:  90 * time.Second,
+		Retries: 2,
+		Gain:    0.25,
+		Mode:    "paused",
+		Pins:    []string{"A1"},
+		Levels:  []uint8{4, 5, 6},
+		Skipped: "skipped",
+	}
+	fm, err := Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := map[string]string{
+		"nm":    "rig",
+		"mp":    "1m30s",
+		"rt":    "2",
+		"gn":    "0.25",
+		"en":    "false",
+		"mo":    "paused",
+		"ip":    "A1",
+		"lv":    "4;5;6",
+		"Other": "",
+	}
+	if !reflect.DeepEqual(fm, want) {
+		t.Errorf("got %v, want %v", fm, want)
+	}
+
+	var got testConfig
+	err = Unmarshal(fm, &got)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	cfg.Skipped = ""
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("round trip got %+v, want %+v", got, cfg)
+	}
+}
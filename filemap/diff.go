@@ -0,0 +1,194 @@
+/*
+NAME
+  diff.go - differences between maps and three-way merging.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  diff.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Op is the kind of change made to a key.
+type Op int
+
+// Kinds of change.
+const (
+	Added Op = iota + 1
+	Changed
+	Removed
+)
+
+func (op Op) String() string {
+	switch op {
+	case Added:
+		return "added"
+	case Changed:
+		return "changed"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Change describes a change to the value of a key. Old is empty for an
+// added key and New is empty for a removed key.
+type Change struct {
+	Op       Op
+	Key      string
+	Old, New string
+}
+
+// String renders the change as "+ key new", "- key old" or
+// "~ key old -> new", with values quoted.
+func (c Change) String() string {
+	switch c.Op {
+	case Added:
+		return fmt.Sprintf("+ %s %q", c.Key, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s %q", c.Key, c.Old)
+	case Changed:
+		return fmt.Sprintf("~ %s %q -> %q", c.Key, c.Old, c.New)
+	}
+	return fmt.Sprintf("? %s", c.Key)
+}
+
+// ChangeSet is a list of changes, sorted by key.
+type ChangeSet []Change
+
+// String renders the changes one per line.
+func (cs ChangeSet) String() string {
+	lines := make([]string, len(cs))
+	for i, c := range cs {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff returns the changes that turn map a into map b, sorted by key.
+func Diff(a, b map[string]string) ChangeSet {
+	var cs ChangeSet
+	for k, v := range a {
+		nv, ok := b[k]
+		switch {
+		case !ok:
+			cs = append(cs, Change{Op: Removed, Key: k, Old: v})
+		case nv != v:
+			cs = append(cs, Change{Op: Changed, Key: k, Old: v, New: nv})
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			cs = append(cs, Change{Op: Added, Key: k, New: v})
+		}
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Key < cs[j].Key })
+	return cs
+}
+
+// Conflict describes a key changed differently by both sides of a merge.
+type Conflict struct {
+	Key    string
+	Local  Change // The local change relative to the base.
+	Remote Change // The remote change relative to the base.
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: local %s, remote %s", c.Key, c.Local, c.Remote)
+}
+
+// Resolver decides the outcome of a conflict, returning the value to use
+// and whether the key is present in the merged map.
+type Resolver func(c Conflict) (value string, present bool)
+
+// Resolution strategies.
+var (
+	// PreferLocal resolves conflicts in favour of the local change.
+	PreferLocal Resolver = func(c Conflict) (string, bool) { return c.Local.New, c.Local.Op != Removed }
+
+	// PreferRemote resolves conflicts in favour of the remote change.
+	PreferRemote Resolver = func(c Conflict) (string, bool) { return c.Remote.New, c.Remote.Op != Removed }
+)
+
+// Merge performs a three-way merge of the local and remote maps, both
+// derived from base. A key changed on only one side takes that side's
+// value, and a key changed identically on both sides takes the common
+// value. A key changed differently on both sides is a conflict, which is
+// settled by resolve, or by PreferLocal if resolve is nil. All conflicts
+// are returned, sorted by key, so that they can be reported.
+func Merge(base, local, remote map[string]string, resolve Resolver) (map[string]string, []Conflict) {
+	if resolve == nil {
+		resolve = PreferLocal
+	}
+	keys := map[string]bool{}
+	for _, fm := range []map[string]string{base, local, remote} {
+		for k := range fm {
+			keys[k] = true
+		}
+	}
+
+	merged := map[string]string{}
+	var conflicts []Conflict
+	for k := range keys {
+		b, inBase := base[k]
+		l, inLocal := local[k]
+		r, inRemote := remote[k]
+		localChanged := inLocal != inBase || l != b
+		remoteChanged := inRemote != inBase || r != b
+
+		var value string
+		var present bool
+		switch {
+		case !remoteChanged || (inLocal == inRemote && l == r):
+			value, present = l, inLocal
+		case !localChanged:
+			value, present = r, inRemote
+		default:
+			c := Conflict{
+				Key:    k,
+				Local:  change(k, b, inBase, l, inLocal),
+				Remote: change(k, b, inBase, r, inRemote),
+			}
+			conflicts = append(conflicts, c)
+			value, present = resolve(c)
+		}
+		if present {
+			merged[k] = value
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Key < conflicts[j].Key })
+	return merged, conflicts
+}
+
+// change returns the change to key from value a to value b, where either
+// may be absent.
+func change(key, a string, hasA bool, b string, hasB bool) Change {
+	switch {
+	case !hasA:
+		return Change{Op: Added, Key: key, New: b}
+	case !hasB:
+		return Change{Op: Removed, Key: key, Old: a}
+	}
+	return Change{Op: Changed, Key: key, Old: a, New: b}
+}
@@ -0,0 +1,108 @@
+/*
+NAME
+  diff_test.go - tests for diff.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  diff_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDiff tests computing and rendering the changes between maps.
+func TestDiff(t *testing.T) {
+	a := map[string]string{"a": "1", "b": "2", "c": "3"}
+	b := map[string]string{"a": "1", "b": "20", "d": "4 5"}
+	want := ChangeSet{
+		{Op: Changed, Key: "b", Old: "2", New: "20"},
+		{Op: Removed, Key: "c", Old: "3"},
+		{Op: Added, Key: "d", New: "4 5"},
+	}
+	got := Diff(a, b)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	const wantText = "~ b \"2\" -> \"20\"\n- c \"3\"\n+ d \"4 5\""
+	if got.String() != wantText {
+		t.Errorf("got text:\n%s\nwant:\n%s", got, wantText)
+	}
+	if got := Diff(a, a); len(got) != 0 {
+		t.Errorf("got %v for identical maps, want no changes", got)
+	}
+}
+
+// TestMerge tests three-way merging and conflict resolution.
+func TestMerge(t *testing.T) {
+	base := map[string]string{"same": "0", "loc": "0", "rem": "0", "both": "0", "clash": "0", "gone": "0", "del": "0"}
+	local := map[string]string{"same": "0", "loc": "1", "rem": "0", "both": "2", "clash": "1", "del": "0", "new": "1"}
+	remote := map[string]string{"same": "0", "loc": "0", "rem": "1", "both": "2", "clash": "2", "new": "2"}
+
+	wantConflicts := []Conflict{
+		{
+			Key:    "clash",
+			Local:  Change{Op: Changed, Key: "clash", Old: "0", New: "1"},
+			Remote: Change{Op: Changed, Key: "clash", Old: "0", New: "2"},
+		},
+		{
+			Key:    "new",
+			Local:  Change{Op: Added, Key: "new", New: "1"},
+			Remote: Change{Op: Added, Key: "new", New: "2"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		resolve Resolver
+		want    map[string]string
+	}{
+		{
+			name: "default",
+			want: map[string]string{"same": "0", "loc": "1", "rem": "1", "both": "2", "clash": "1", "new": "1"},
+		},
+		{
+			name:    "remote",
+			resolve: PreferRemote,
+			want:    map[string]string{"same": "0", "loc": "1", "rem": "1", "both": "2", "clash": "2", "new": "2"},
+		},
+		{
+			name:    "custom",
+			resolve: func(c Conflict) (string, bool) { return c.Local.New + c.Remote.New, c.Key != "new" },
+			want:    map[string]string{"same": "0", "loc": "1", "rem": "1", "both": "2", "clash": "12"},
+		},
+	}
+	for _, test := range tests {
+		got, conflicts := Merge(base, local, remote, test.resolve)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+		if !reflect.DeepEqual(conflicts, wantConflicts) {
+			t.Errorf("%s: got conflicts %v, want %v", test.name, conflicts, wantConflicts)
+		}
+	}
+
+	// A removal conflicting with a change.
+	_, conflicts := Merge(map[string]string{"k": "0"}, map[string]string{}, map[string]string{"k": "1"}, nil)
+	if len(conflicts) != 1 || conflicts[0].Local.Op != Removed || conflicts[0].Remote.Op != Changed {
+		t.Errorf("got conflicts %v, want removal against change", conflicts)
+	}
+}
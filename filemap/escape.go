@@ -0,0 +1,319 @@
+/*
+NAME
+  escape.go - escaping and quoting of keys and values containing
+  delimiters.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  escape.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned in strict mode for ambiguous input.
+var (
+	ErrUnterminatedQuote = errors.New("filemap: unterminated quote")
+	ErrTextAfterQuote    = errors.New("filemap: text after closing quote")
+	ErrExtraDelimiter    = errors.New("filemap: unescaped minor delimiter in value")
+	ErrDuplicateKey      = errors.New("filemap: duplicate key")
+	ErrEmptyDelimiter    = errors.New("filemap: empty delimiter")
+)
+
+// token is an item of a string split by the major delimiter, with its
+// unescaped key and value.
+type token struct {
+	raw        string // The text of the item, as read.
+	key, value string // The unescaped key and value.
+	comment    bool   // Indicates the item is a comment or blank.
+}
+
+// scanner splits strings into tokens, honouring escapes and quotes.
+type scanner struct {
+	str, major, minor string
+	comments          bool // Treat blank items and items starting with '#' as comments.
+}
+
+// scan returns the tokens of s.str and whether it ended with the major
+// delimiter. Empty items are omitted unless comments are enabled. The
+// first ambiguity found, if any, is returned as an error, but does not
+// stop the scan. If either delimiter is empty, escapes and quotes cannot
+// be recognised, so the string is split literally and ErrEmptyDelimiter
+// is returned.
+func (s *scanner) scan() ([]token, bool, error) {
+	if s.major == "" || s.minor == "" {
+		return s.scanLiteral()
+	}
+
+	var (
+		tokens []token
+		err    error
+	)
+	i := 0
+	for i < len(s.str) {
+		var t token
+		if s.comments && s.isComment(i) {
+			end := strings.Index(s.str[i:], s.major)
+			if end < 0 {
+				end = len(s.str) - i
+			}
+			t = token{raw: s.str[i : i+end], comment: true}
+			i += end
+		} else {
+			var e error
+			t, i, e = s.item(i)
+			if e != nil && err == nil {
+				err = fmt.Errorf("%w in %q", e, t.raw)
+			}
+		}
+		terminated := strings.HasPrefix(s.str[i:], s.major)
+		if terminated {
+			i += len(s.major)
+		}
+		if t.raw != "" || s.comments {
+			tokens = append(tokens, t)
+		}
+		if terminated && i == len(s.str) {
+			return tokens, true, err
+		}
+	}
+	return tokens, false, err
+}
+
+// scanLiteral splits s.str without regard to escapes or quotes, as Split
+// did before escaping was supported, for use with empty delimiters. Only
+// the first two parts of an item split by the minor delimiter are used.
+func (s *scanner) scanLiteral() ([]token, bool, error) {
+	var tokens []token
+	terminated := s.major != "" && strings.HasSuffix(s.str, s.major)
+	items := strings.Split(s.str, s.major)
+	if terminated {
+		items = items[:len(items)-1]
+	}
+	for _, it := range items {
+		trimmed := strings.TrimSpace(it)
+		if s.comments && (trimmed == "" || strings.HasPrefix(trimmed, commentPrefix)) {
+			tokens = append(tokens, token{raw: it, comment: true})
+			continue
+		}
+		if it == "" {
+			continue
+		}
+		t := token{raw: it}
+		ss := strings.Split(it, s.minor)
+		t.key = ss[0]
+		if len(ss) > 1 {
+			t.value = ss[1]
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, terminated, ErrEmptyDelimiter
+}
+
+// isComment returns true if the item starting at i is blank or a comment.
+func (s *scanner) isComment(i int) bool {
+	end := strings.Index(s.str[i:], s.major)
+	if end < 0 {
+		end = len(s.str) - i
+	}
+	trimmed := strings.TrimSpace(s.str[i : i+end])
+	return trimmed == "" || strings.HasPrefix(trimmed, commentPrefix)
+}
+
+// item scans the item starting at i, returning its token and the index of
+// the end of the item, i.e. of the following major delimiter or the end of
+// the string.
+func (s *scanner) item(i int) (token, int, error) {
+	var (
+		buf        strings.Builder
+		start      = i
+		fieldStart = i
+		inValue    bool
+		inQuote    bool
+		afterQuote bool
+		err        error
+		t          token
+	)
+	setErr := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+
+	for i < len(s.str) {
+		c := s.str[i]
+		if inQuote {
+			switch {
+			case c == '\\' && i+1 < len(s.str) && (s.str[i+1] == '"' || s.str[i+1] == '\\'):
+				buf.WriteByte(s.str[i+1])
+				i += 2
+			case c == '"':
+				inQuote = false
+				afterQuote = true
+				i++
+			default:
+				buf.WriteByte(c)
+				i++
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(s.str[i:], s.major):
+			goto done
+		case strings.HasPrefix(s.str[i:], s.minor):
+			if inValue {
+				setErr(ErrExtraDelimiter)
+				buf.WriteString(s.minor)
+				i += len(s.minor)
+				continue
+			}
+			t.key = buf.String()
+			buf.Reset()
+			inValue = true
+			afterQuote = false
+			i += len(s.minor)
+			fieldStart = i
+		case c == '\\':
+			n := s.escaped(i + 1)
+			if n == 0 {
+				buf.WriteByte(c)
+				i++
+				continue
+			}
+			buf.WriteString(s.str[i+1 : i+1+n])
+			i += 1 + n
+		case c == '"' && i == fieldStart:
+			inQuote = true
+			i++
+		default:
+			if afterQuote {
+				setErr(ErrTextAfterQuote)
+				afterQuote = false
+			}
+			buf.WriteByte(c)
+			i++
+		}
+	}
+done:
+	if inQuote {
+		setErr(ErrUnterminatedQuote)
+	}
+	if inValue {
+		t.value = buf.String()
+	} else {
+		t.key = buf.String()
+	}
+	t.raw = s.str[start:i]
+	return t, i, err
+}
+
+// escaped returns the length of the escaped text at i, i.e. of a delimiter,
+// backslash or double quote, or zero if a backslash before i is literal.
+func (s *scanner) escaped(i int) int {
+	switch {
+	case strings.HasPrefix(s.str[i:], s.major):
+		return len(s.major)
+	case strings.HasPrefix(s.str[i:], s.minor):
+		return len(s.minor)
+	case i < len(s.str) && (s.str[i] == '\\' || s.str[i] == '"'):
+		return 1
+	}
+	return 0
+}
+
+// escape returns the value str escaped for writing before the major
+// delimiter, as for escapeBefore.
+func escape(str, major, minor string) string {
+	return escapeBefore(str, major, minor, major)
+}
+
+// escapeBefore returns str with delimiters escaped by a backslash, such that
+// scanning the result followed by the delimiter next yields str. A leading
+// double quote is escaped so that it does not start a quoted string, and a
+// backslash is escaped only where it would otherwise be read as an escape.
+// If a delimiter would be matched across the end of str and the start of
+// next, e.g. "b:" followed by "::", no escape can prevent it, so str is
+// quoted instead. If either delimiter is empty, str is returned unchanged,
+// since escapes are then not recognised.
+func escapeBefore(str, major, minor, next string) string {
+	if major == "" || minor == "" {
+		return str
+	}
+	var (
+		b        strings.Builder
+		combined = str + next
+	)
+	for i := 0; i < len(str); {
+		// Find the delimiter the scanner would match here, if any.
+		var delim string
+		switch {
+		case strings.HasPrefix(combined[i:], major):
+			delim = major
+		case strings.HasPrefix(combined[i:], minor):
+			delim = minor
+		}
+		switch {
+		case delim != "" && i+len(delim) > len(str):
+			return quote(str)
+		case delim != "":
+			b.WriteString(`\` + delim)
+			i += len(delim)
+		case i == 0 && str[i] == '"':
+			b.WriteString(`\"`)
+			i++
+		case str[i] == '\\':
+			rest := combined[i+1:]
+			if rest[0] == '\\' || rest[0] == '"' || strings.HasPrefix(rest, major) || strings.HasPrefix(rest, minor) {
+				b.WriteString(`\\`)
+			} else {
+				b.WriteByte('\\')
+			}
+			i++
+		default:
+			b.WriteByte(str[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// escapeKey returns key escaped for writing before the minor delimiter, as
+// for escapeBefore, except that a key that is
+// blank or starts with '#', ignoring leading white space, is quoted so
+// that its item is not read back as a blank or comment item by Parse.
+func escapeKey(key, major, minor string) string {
+	if major == "" || minor == "" {
+		return key
+	}
+	trimmed := strings.TrimSpace(key)
+	if trimmed == "" || strings.HasPrefix(trimmed, commentPrefix) {
+		return quote(key)
+	}
+	return escapeBefore(key, major, minor, minor)
+}
+
+// quote returns str in double quotes, escaping backslashes and quotes.
+func quote(str string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str) + `"`
+}
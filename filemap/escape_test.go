@@ -0,0 +1,227 @@
+/*
+NAME
+  escape_test.go - tests for escape.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  escape_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSplitEscapes tests splitting strings containing escapes and quotes.
+func TestSplitEscapes(t *testing.T) {
+	tests := []struct {
+		str  string
+		want map[string]string
+		err  error
+	}{
+		{
+			str:  `key1 a\ b` + "\n" + `key2 "c d"` + "\n",
+			want: map[string]string{"key1": "a b", "key2": "c d"},
+		},
+		{
+			str:  `"my key" line1\` + "\n" + `line2` + "\n",
+			want: map[string]string{"my key": "line1\nline2"},
+		},
+		{
+			str:  `path C:\dir\\` + "\n" + `quote \"x` + "\n" + `inner a"b` + "\n",
+			want: map[string]string{"path": `C:\dir\`, "quote": `"x`, "inner": `a"b`},
+		},
+		{
+			str:  "key a b\n",
+			want: map[string]string{"key": "a b"},
+			err:  ErrExtraDelimiter,
+		},
+		{
+			str:  `key "a b` + "\nother x\n",
+			want: map[string]string{"key": "a b\nother x\n"},
+			err:  ErrUnterminatedQuote,
+		},
+		{
+			str:  `key "a"b` + "\n",
+			want: map[string]string{"key": "ab"},
+			err:  ErrTextAfterQuote,
+		},
+		{
+			str:  "key a\nkey b\n",
+			want: map[string]string{"key": "b"},
+			err:  ErrDuplicateKey,
+		},
+	}
+
+	for i, test := range tests {
+		got := Split(test.str, "\n", " ")
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("test %d: unexpected map from Split: got: %q want: %q", i, got, test.want)
+		}
+		strict, err := SplitStrict(test.str, "\n", " ")
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: unexpected error from SplitStrict: got: %v want: %v", i, err, test.err)
+		}
+		if err == nil && !reflect.DeepEqual(strict, test.want) {
+			t.Errorf("test %d: unexpected map from SplitStrict: got: %q want: %q", i, strict, test.want)
+		}
+	}
+}
+
+// TestEscapeRoundTrip tests that maps with keys and values containing
+// delimiters, quotes and backslashes, and keys that look like comments,
+// survive writing and reading.
+func TestEscapeRoundTrip(t *testing.T) {
+	fm := map[string]string{
+		"plain":     "value",
+		"has space": "a b c",
+		"newline":   "line1\nline2",
+		"quote":     `"quoted" value`,
+		"backslash": `C:\dir\`,
+		"escapes":   `\\ \" \`,
+		"empty":     "",
+		"#hash":     "not a comment",
+		"  #indent": "not a comment",
+		"  ":        "blank key",
+		"":          "empty key",
+	}
+	file := filepath.Join(t.TempDir(), "test")
+	err := WriteTo(file, "\n", " ", fm, nil)
+	if err != nil {
+		t.Fatalf("WriteTo returned with error %v", err)
+	}
+	got, err := ReadFromStrict(file, "\n", " ")
+	if err != nil {
+		t.Fatalf("ReadFromStrict returned with error %v", err)
+	}
+	if !reflect.DeepEqual(got, fm) {
+		t.Errorf("map not preserved:\ngot:  %q\nwant: %q", got, fm)
+	}
+
+	// The same should apply to values set in a File.
+	f := Parse("# comment with \"unbalanced quote\n", "\n", " ")
+	for k, v := range fm {
+		f.Set(k, v)
+	}
+	f = Parse(f.String(), "\n", " ")
+	if !reflect.DeepEqual(f.Map(), fm) {
+		t.Errorf("File map not preserved:\ngot:  %q\nwant: %q", f.Map(), fm)
+	}
+}
+
+// TestSplitOverlappingDelimiters tests that keys and values survive writing
+// and reading when a delimiter could be matched across the end of a key or
+// value and the delimiter that follows it.
+func TestSplitOverlappingDelimiters(t *testing.T) {
+	tests := []struct {
+		major, minor string
+		fm           map[string]string
+	}{
+		{
+			major: "\n",
+			minor: "::",
+			fm:    map[string]string{"b:": "v", ":": ":", "a:::": "x:", `c\`: "::"},
+		},
+		{
+			major: ";;",
+			minor: "=",
+			fm:    map[string]string{"a": "v;", "b;": ";", "c": "x;;;", "d=": `e\`},
+		},
+		{
+			major: ";;",
+			minor: "::",
+			fm:    map[string]string{"b:": "v;", ";": ":", ":;": ";:"},
+		},
+	}
+	for _, test := range tests {
+		var str string
+		for _, kv := range Sort(test.fm, nil) {
+			str += escapeKey(kv.Key, test.major, test.minor) + test.minor + escape(kv.Value, test.major, test.minor) + test.major
+		}
+		got, err := SplitStrict(str, test.major, test.minor)
+		if err != nil {
+			t.Errorf("%q, %q: SplitStrict of %q returned error %v", test.major, test.minor, str, err)
+		}
+		if !reflect.DeepEqual(got, test.fm) {
+			t.Errorf("%q, %q: map not preserved by %q:\ngot:  %q\nwant: %q", test.major, test.minor, str, got, test.fm)
+		}
+	}
+
+	// Exhaustively check short keys and values made of delimiter characters.
+	const chars = ":;a"
+	var strs []string
+	for _, a := range " " + chars {
+		for _, b := range " " + chars {
+			for _, c := range chars {
+				strs = append(strs, strings.TrimSpace(string([]rune{a, b, c})))
+			}
+		}
+	}
+	for _, d := range [][2]string{{";;", "::"}, {";;", ":"}, {":;", ";"}} {
+		for _, k := range strs {
+			for _, v := range strs {
+				str := escapeKey(k, d[0], d[1]) + d[1] + escape(v, d[0], d[1]) + d[0]
+				got, err := SplitStrict(str, d[0], d[1])
+				if err != nil || got[k] != v || len(got) != 1 {
+					t.Fatalf("%q, %q: key %q, value %q written as %q read as %q, %v", d[0], d[1], k, v, str, got, err)
+				}
+			}
+		}
+	}
+}
+
+// TestEmptyDelimiter checks that empty delimiters are rejected in strict
+// mode and split literally otherwise, rather than never returning.
+func TestEmptyDelimiter(t *testing.T) {
+	tests := []struct {
+		str, major, minor string
+		want              map[string]string
+	}{
+		{"a b\nc d", "\n", "", map[string]string{"a": " ", "c": " "}},
+		{"ab", "", " ", map[string]string{"a": "", "b": ""}},
+		{"", "\n", "", map[string]string{}},
+	}
+	for _, test := range tests {
+		got := Split(test.str, test.major, test.minor)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Split(%q, %q, %q) = %v, want %v", test.str, test.major, test.minor, got, test.want)
+		}
+		_, err := SplitStrict(test.str, test.major, test.minor)
+		if !errors.Is(err, ErrEmptyDelimiter) {
+			t.Errorf("SplitStrict(%q, %q, %q) returned error %v, want %v", test.str, test.major, test.minor, err, ErrEmptyDelimiter)
+		}
+	}
+
+	f := Parse("# comment\na b\n", "\n", "")
+	f.Set("c", "d\"")
+	if got, want := f.String(), "# comment\na b\ncd\"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	ini := ParseINI("[s]\na b\n", "\n", "")
+	if v, ok := ini.Section("s").Get("a"); !ok || v != " " {
+		t.Errorf("got %q, %v from INI, want %q", v, ok, " ")
+	}
+	if got := escape(`a"\b`, "", " "); got != `a"\b` {
+		t.Errorf("escape with empty delimiter changed %q to %q", `a"\b`, got)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+NAME
+  file.go - a document model for maps stored in files that preserves
+  comments, blank lines and ordering.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  file.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// commentPrefix starts a comment item, ignoring leading white space.
+const commentPrefix = "#"
+
+// File represents a map stored in a file as an ordered list of items,
+// including comments and blank items, so that the file can be modified
+// and written back without losing anything. Items that are not modified
+// are written back exactly as they were read.
+//
+// An item whose first non-space character is '#' is a comment. If a key
+// occurs more than once, the last occurrence is used, as for Split.
+type File struct {
+	major, minor string
+	items        []item
+	terminated   bool // Indicates the content ended with the major delimiter.
+}
+
+// item is a single item of a File, i.e. the text between major delimiters.
+type item struct {
+	raw        string // The text of the item.
+	key, value string // The key and value, if the item is an entry.
+	entry      bool   // Indicates the item is an entry, rather than a comment or blank.
+}
+
+// ReadFile reads a File from file. The major delimiter separates items
+// and the minor delimiter separates keys from values.
+func ReadFile(file, major, minor string) (*File, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(content), major, minor), nil
+}
+
+// Parse parses str into a File. The major delimiter separates items and
+// the minor delimiter separates keys from values. Keys and values are
+// unescaped as for Split.
+func Parse(str, major, minor string) *File {
+	f := &File{major: major, minor: minor}
+	sc := scanner{str: str, major: major, minor: minor, comments: true}
+	tokens, terminated, _ := sc.scan()
+	for _, t := range tokens {
+		f.items = append(f.items, item{raw: t.raw, key: t.key, value: t.value, entry: !t.comment})
+	}
+	f.terminated = terminated
+	return f
+}
+
+// find returns the index of the last entry for key, or -1 if there is none.
+func (f *File) find(key string) int {
+	for i := len(f.items) - 1; i >= 0; i-- {
+		if f.items[i].entry && f.items[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the value for key and true, or an empty string and false if
+// key is not present.
+func (f *File) Get(key string) (string, bool) {
+	i := f.find(key)
+	if i < 0 {
+		return "", false
+	}
+	return f.items[i].value, true
+}
+
+// Set sets the value for key. If key is present, its entry is replaced in
+// place, otherwise a new entry is appended. The key and value are escaped
+// as for WriteTo.
+func (f *File) Set(key, value string) {
+	raw := escapeKey(key, f.major, f.minor) + f.minor + escape(value, f.major, f.minor)
+	it := item{raw: raw, key: key, value: value, entry: true}
+	i := f.find(key)
+	if i >= 0 {
+		f.items[i] = it
+		return
+	}
+	if len(f.items) == 0 {
+		f.terminated = true
+	}
+	f.items = append(f.items, it)
+}
+
+// Delete removes all entries for key.
+func (f *File) Delete(key string) {
+	items := f.items[:0]
+	for _, it := range f.items {
+		if it.entry && it.key == key {
+			continue
+		}
+		items = append(items, it)
+	}
+	f.items = items
+}
+
+// Keys returns the keys of the File in the order in which they first occur.
+func (f *File) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, it := range f.items {
+		if it.entry && !seen[it.key] {
+			seen[it.key] = true
+			keys = append(keys, it.key)
+		}
+	}
+	return keys
+}
+
+// Map returns the entries of the File as a map.
+func (f *File) Map() map[string]string {
+	fm := map[string]string{}
+	for _, it := range f.items {
+		if it.entry {
+			fm[it.key] = it.value
+		}
+	}
+	return fm
+}
+
+// String returns the content of the File.
+func (f *File) String() string {
+	var b strings.Builder
+	for i, it := range f.items {
+		if i > 0 {
+			b.WriteString(f.major)
+		}
+		b.WriteString(it.raw)
+	}
+	if f.terminated && len(f.items) != 0 {
+		b.WriteString(f.major)
+	}
+	return b.String()
+}
+
+// WriteTo implements io.WriterTo, writing the content of the File to w.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, f.String())
+	return int64(n), err
+}
+
+// Save writes the content of the File to file atomically using WriteFile.
+func (f *File) Save(file string) error {
+	return WriteFile(file, []byte(f.String()), defaultPerm, false)
+}
@@ -0,0 +1,122 @@
+/*
+NAME
+  file_test.go - tests for file.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  file_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConf = `# netsender configuration.
+ma 00:00:00:00:00:01
+
+# Device key, do not share.
+dk 12345
+  # Indented comment.
+wi wlan0,secret
+`
+
+// TestFileRoundTrip tests that untouched content is written back unchanged.
+func TestFileRoundTrip(t *testing.T) {
+	for _, content := range []string{
+		"",
+		testConf,
+		"key1 value1\nkey2",
+		"\n\n# Only comments\n",
+	} {
+		f := Parse(content, "\n", " ")
+		if got := f.String(); got != content {
+			t.Errorf("content not preserved:\ngot:  %q\nwant: %q", got, content)
+		}
+	}
+}
+
+// TestFileEdit tests getting, setting and deleting entries.
+func TestFileEdit(t *testing.T) {
+	f := Parse(testConf, "\n", " ")
+
+	if v, ok := f.Get("wi"); !ok || v != "wlan0,secret" {
+		t.Errorf("unexpected value for wi: %q %v", v, ok)
+	}
+	if _, ok := f.Get("# Device"); ok {
+		t.Error("did not expect comment to be an entry")
+	}
+	if got, want := f.Keys(), []string{"ma", "dk", "wi"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected keys: got: %v want: %v", got, want)
+	}
+
+	f.Set("dk", "67890")
+	f.Set("mp", "60")
+	f.Delete("ma")
+
+	const want = `# netsender configuration.
+
+# Device key, do not share.
+dk 67890
+  # Indented comment.
+wi wlan0,secret
+mp 60
+`
+	if got := f.String(); got != want {
+		t.Errorf("unexpected content after edit:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := f.Map(), map[string]string{"dk": "67890", "wi": "wlan0,secret", "mp": "60"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected map: got: %v want: %v", got, want)
+	}
+
+	// Setting a key in an empty file should produce a terminated file.
+	f = Parse("", "\n", " ")
+	f.Set("key", "value")
+	if got := f.String(); got != "key value\n" {
+		t.Errorf("unexpected content for new file: %q", got)
+	}
+}
+
+// TestFileSave tests reading and saving a File.
+func TestFileSave(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "netsender.conf")
+	err := os.WriteFile(file, []byte(testConf), 0666)
+	if err != nil {
+		t.Fatalf("could not write test file: %v", err)
+	}
+	f, err := ReadFile(file, "\n", " ")
+	if err != nil {
+		t.Fatalf("ReadFile returned with error %v", err)
+	}
+	f.Set("mp", "60")
+	err = f.Save(file)
+	if err != nil {
+		t.Fatalf("Save returned with error %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("could not read test file: %v", err)
+	}
+	if string(content) != testConf+"mp 60\n" {
+		t.Errorf("unexpected saved content:\n%s", content)
+	}
+}
@@ -38,10 +38,19 @@ LICENSE
 //	"key3": "",
 //   }
 //
-// NB: Keys and values must not contain strings used as delimiters.
+// Keys and values containing delimiters are escaped with a backslash
+// when written, e.g. "a\ b" for the value "a b" with a space minor
+// delimiter. When read, a backslash escapes a following delimiter,
+// backslash or double quote, and is otherwise literal. A key or value
+// may also be enclosed in double quotes, within which delimiters are
+// literal, e.g. "a b". If a value contains an unescaped minor
+// delimiter, it is kept as part of the value. The strict functions,
+// SplitStrict and ReadFromStrict, return an error for such ambiguous
+// input instead.
 package filemap
 
 import (
+	"fmt"
 	"io/ioutil"
 	"sort"
 	"strings"
@@ -63,41 +72,63 @@ func ReadFrom(file, major, minor string) (map[string]string, error) {
 	return Split(string(content), major, minor), nil
 }
 
+// ReadFromStrict is like ReadFrom but returns an error if the content of
+// the file is ambiguous. See SplitStrict.
+func ReadFromStrict(file, major, minor string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return SplitStrict(string(content), major, minor)
+}
+
 // WriteTo writes a map[string]string to a file. The major delimiter
 // separates key-value pairs and the minor delimiter separates keys
-// from values. See Sort() for a description of the order.
+// from values. See Sort() for a description of the order. Delimiters
+// within keys and values are escaped, and keys that would be read as
+// comments by ReadFile are quoted. The file is written atomically using
+// WriteFile.
 func WriteTo(file, major, minor string, fm map[string]string, order []string) error {
 	var content string
 
 	for _, kv := range Sort(fm, order) {
-		content += kv.Key + minor + kv.Value + major
+		content += escapeKey(kv.Key, major, minor) + minor + escape(kv.Value, major, minor) + major
 	}
-	return ioutil.WriteFile(file, []byte(content), 0666)
+	return WriteFile(file, []byte(content), defaultPerm, false)
 }
 
 // Split splits a string twice into a map. The major delimiter
 // separates key-value pairs and the minor delimiter separates keys
-// from values.
+// from values. Escapes and quotes are removed from keys and values.
 func Split(str string, major string, minor string) map[string]string {
+	fm, _ := split(str, major, minor, false)
+	return fm
+}
+
+// SplitStrict is like Split but returns an error if str is ambiguous,
+// i.e. if a value contains an unescaped minor delimiter, a quote is
+// unterminated or followed by text, a key occurs more than once, or
+// either delimiter is empty.
+func SplitStrict(str string, major string, minor string) (map[string]string, error) {
+	return split(str, major, minor, true)
+}
+
+// split splits str into a map, returning the first ambiguity found if
+// strict is true.
+func split(str, major, minor string, strict bool) (map[string]string, error) {
 	fm := map[string]string{}
-	if str == "" {
-		return fm
+	s := scanner{str: str, major: major, minor: minor}
+	tokens, _, err := s.scan()
+	if err != nil && strict {
+		return nil, err
 	}
-	for _, item := range strings.Split(str, major) {
-		if len(item) == 0 {
-			continue
-		}
-		ss := strings.Split(item, minor)
-		if len(ss) == 0 {
-			continue
-		}
-		if len(ss) == 1 {
-			fm[ss[0]] = ""
-		} else {
-			fm[ss[0]] = ss[1]
+	for _, t := range tokens {
+		if _, ok := fm[t.key]; ok && strict {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, t.key)
 		}
+		fm[t.key] = t.value
 	}
-	return fm
+	return fm, nil
 }
 
 // Sort produces a sorted slice of the map's keys and values. If
@@ -0,0 +1,272 @@
+/*
+NAME
+  ini.go - maps with sections and multi-valued keys, as found in INI
+  files.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  ini.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// INI represents a map divided into sections by headers of the form
+// "[name]", in which keys may be repeated to hold several values. Entries
+// before the first header belong to the global section, named "". For
+// example, with newline and "=" delimiters:
+//
+//	mode=normal
+//
+//	[camera]
+//	pin=A0
+//	pin=A1
+//
+// Comments and blank items are skipped when reading and are not written.
+// Sections with the same name are merged.
+type INI struct {
+	major, minor string
+	sections     []*Section
+}
+
+// Section is a named section of an INI, holding ordered entries.
+type Section struct {
+	Name    string
+	entries []KeyValue
+}
+
+// ReadINI reads an INI from file. The major delimiter separates items and
+// the minor delimiter separates keys from values.
+func ReadINI(file, major, minor string) (*INI, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseINI(string(content), major, minor), nil
+}
+
+// ParseINI parses str into an INI. Keys and values are unescaped as for
+// Split.
+func ParseINI(str, major, minor string) *INI {
+	ini := NewINI(major, minor)
+	sec := ini.sections[0]
+	sc := scanner{str: str, major: major, minor: minor, comments: true}
+	tokens, _, _ := sc.scan()
+	for _, t := range tokens {
+		if t.comment {
+			continue
+		}
+		if name, ok := header(t.raw); ok {
+			sec = ini.AddSection(name)
+			continue
+		}
+		sec.Add(t.key, t.value)
+	}
+	return ini
+}
+
+// header returns the section name of raw and true if it is a header.
+func header(raw string) (string, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if len(trimmed) < 2 || trimmed[0] != '[' || trimmed[len(trimmed)-1] != ']' {
+		return "", false
+	}
+	return strings.TrimSpace(trimmed[1 : len(trimmed)-1]), true
+}
+
+// NewINI returns an empty INI using the given delimiters.
+func NewINI(major, minor string) *INI {
+	return &INI{major: major, minor: minor, sections: []*Section{{}}}
+}
+
+// Section returns the section with the given name, or nil if there is
+// none. The global section, named "", always exists.
+func (ini *INI) Section(name string) *Section {
+	for _, s := range ini.sections {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// AddSection returns the section with the given name, adding it after the
+// existing sections if there is none.
+func (ini *INI) AddSection(name string) *Section {
+	s := ini.Section(name)
+	if s == nil {
+		s = &Section{Name: name}
+		ini.sections = append(ini.sections, s)
+	}
+	return s
+}
+
+// DeleteSection removes the section with the given name. The global
+// section is emptied rather than removed.
+func (ini *INI) DeleteSection(name string) {
+	if name == "" {
+		ini.sections[0].entries = nil
+		return
+	}
+	for i, s := range ini.sections {
+		if s.Name == name {
+			ini.sections = append(ini.sections[:i], ini.sections[i+1:]...)
+			return
+		}
+	}
+}
+
+// Sections returns the names of the sections in order, starting with the
+// global section.
+func (ini *INI) Sections() []string {
+	names := make([]string, len(ini.sections))
+	for i, s := range ini.sections {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// String returns the content of the INI. Keys and values are escaped as
+// for WriteTo, and keys that would also be read as headers are quoted.
+func (ini *INI) String() string {
+	var items []string
+	for _, s := range ini.sections {
+		if s.Name != "" {
+			if len(items) != 0 {
+				items = append(items, "")
+			}
+			items = append(items, "["+s.Name+"]")
+		}
+		for _, kv := range s.entries {
+			key := escapeKey(kv.Key, ini.major, ini.minor)
+			if strings.HasPrefix(strings.TrimSpace(kv.Key), "[") {
+				key = quote(kv.Key)
+			}
+			items = append(items, key+ini.minor+escape(kv.Value, ini.major, ini.minor))
+		}
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return strings.Join(items, ini.major) + ini.major
+}
+
+// WriteTo implements io.WriterTo, writing the content of the INI to w.
+func (ini *INI) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, ini.String())
+	return int64(n), err
+}
+
+// Save writes the content of the INI to file atomically using WriteFile.
+func (ini *INI) Save(file string) error {
+	return WriteFile(file, []byte(ini.String()), defaultPerm, false)
+}
+
+// Get returns the last value for key and true, or an empty string and
+// false if key is not present. Using the last value matches Split.
+func (s *Section) Get(key string) (string, bool) {
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if s.entries[i].Key == key {
+			return s.entries[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Values returns all values for key in order.
+func (s *Section) Values(key string) []string {
+	var values []string
+	for _, kv := range s.entries {
+		if kv.Key == key {
+			values = append(values, kv.Value)
+		}
+	}
+	return values
+}
+
+// Add appends a value for key, keeping any existing values.
+func (s *Section) Add(key, value string) {
+	s.entries = append(s.entries, KeyValue{Key: key, Value: value})
+}
+
+// Set replaces all values for key with value. The entry takes the place of
+// the first existing value, if any, and is otherwise appended.
+func (s *Section) Set(key, value string) {
+	entries := s.entries[:0]
+	set := false
+	for _, kv := range s.entries {
+		if kv.Key != key {
+			entries = append(entries, kv)
+			continue
+		}
+		if !set {
+			entries = append(entries, KeyValue{Key: key, Value: value})
+			set = true
+		}
+	}
+	if !set {
+		entries = append(entries, KeyValue{Key: key, Value: value})
+	}
+	s.entries = entries
+}
+
+// Delete removes all values for key.
+func (s *Section) Delete(key string) {
+	entries := s.entries[:0]
+	for _, kv := range s.entries {
+		if kv.Key != key {
+			entries = append(entries, kv)
+		}
+	}
+	s.entries = entries
+}
+
+// Keys returns the keys of the section in the order in which they first
+// occur.
+func (s *Section) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, kv := range s.entries {
+		if !seen[kv.Key] {
+			seen[kv.Key] = true
+			keys = append(keys, kv.Key)
+		}
+	}
+	return keys
+}
+
+// Entries returns a copy of the entries of the section in order,
+// including repeated keys.
+func (s *Section) Entries() []KeyValue {
+	return append([]KeyValue(nil), s.entries...)
+}
+
+// Map returns the section as a flat map, using the last value of repeated
+// keys.
+func (s *Section) Map() map[string]string {
+	fm := map[string]string{}
+	for _, kv := range s.entries {
+		fm[kv.Key] = kv.Value
+	}
+	return fm
+}
@@ -0,0 +1,111 @@
+/*
+NAME
+  ini_test.go - tests for ini.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  ini_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testINI = `# Global settings.
+mode=normal
+
+[camera]
+pin=A0
+pin=A1
+res=720
+
+[ audio ]
+rate=48000
+[camera]
+pin=D0
+`
+
+// TestParseINI tests reading sections and multi-valued keys.
+func TestParseINI(t *testing.T) {
+	ini := ParseINI(testINI, "\n", "=")
+
+	if got, want := ini.Sections(), []string{"", "camera", "audio"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sections() = %v, want %v", got, want)
+	}
+	if got, want := ini.Section("").Map(), map[string]string{"mode": "normal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("global section = %v, want %v", got, want)
+	}
+
+	cam := ini.Section("camera")
+	if got, want := cam.Values("pin"), []string{"A0", "A1", "D0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values(pin) = %v, want %v", got, want)
+	}
+	if v, ok := cam.Get("pin"); !ok || v != "D0" {
+		t.Errorf("Get(pin) = %q, %v, want D0, true", v, ok)
+	}
+	if got, want := cam.Keys(), []string{"pin", "res"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if ini.Section("video") != nil {
+		t.Errorf("unexpected section video")
+	}
+}
+
+// TestINIWrite tests modifying and writing an INI.
+func TestINIWrite(t *testing.T) {
+	ini := ParseINI(testINI, "\n", "=")
+	cam := ini.Section("camera")
+	cam.Set("pin", "A2")
+	cam.Add("pin", "A3")
+	cam.Delete("res")
+	ini.DeleteSection("audio")
+	ini.AddSection("video").Add("[odd]", "a=b")
+
+	const want = `mode=normal
+
+[camera]
+pin=A2
+pin=A3
+
+[video]
+"[odd]"=a\=b
+`
+	if got := ini.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	file := filepath.Join(t.TempDir(), "test.ini")
+	err := ini.Save(file)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got, err := ReadINI(file, "\n", "=")
+	if err != nil {
+		t.Fatalf("ReadINI failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, ini) {
+		t.Errorf("round trip got %+v, want %+v", got, ini)
+	}
+	if v, _ := got.Section("video").Get("[odd]"); v != "a=b" {
+		t.Errorf("got %q for quoted key, want a=b", v)
+	}
+}
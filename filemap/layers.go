@@ -0,0 +1,153 @@
+/*
+NAME
+  layers.go - layered configuration from defaults, files, environment
+  variables and flags.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  layers.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+// Names of layers other than files, which are named by their path.
+const (
+	EnvLayer  = "env"
+	FlagLayer = "flags"
+)
+
+// layer is a named map of configuration values.
+type layer struct {
+	name string
+	fm   map[string]string
+}
+
+// Layers merges maps from several sources, with later layers taking
+// precedence over earlier ones. Layers are typically added in the order
+// defaults, files, environment and flags, for example:
+//
+//	var l filemap.Layers
+//	l.AddMap("defaults", defaults)
+//	err := l.AddFile("/etc/netsender.conf", "\n", " ")
+//	...
+//	l.AddEnv("NETSENDER_")
+//	l.AddFlags(flag.CommandLine)
+//	value, source, ok := l.Get("mp")
+//
+// The zero value is an empty Layers ready to use.
+type Layers struct {
+	layers []layer
+}
+
+// AddMap adds the map fm as a layer with the given name.
+func (l *Layers) AddMap(name string, fm map[string]string) {
+	copied := make(map[string]string, len(fm))
+	for k, v := range fm {
+		copied[k] = v
+	}
+	l.layers = append(l.layers, layer{name: name, fm: copied})
+}
+
+// AddFile adds the map read from file as a layer named by the file's path.
+// The file is read with ReadFile, so comments, such as those of a schema
+// template, are ignored. A file that does not exist is skipped, so that
+// configuration files may be optional.
+func (l *Layers) AddFile(file, major, minor string) error {
+	f, err := ReadFile(file, major, minor)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	l.layers = append(l.layers, layer{name: file, fm: f.Map()})
+	return nil
+}
+
+// AddEnv adds a layer named EnvLayer holding the environment variables
+// whose names begin with prefix. The key of each is the rest of its name
+// in lower case, so with the prefix "NETSENDER_" the variable NETSENDER_MP
+// sets the key "mp".
+func (l *Layers) AddEnv(prefix string) {
+	fm := map[string]string{}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix) || name == prefix {
+			continue
+		}
+		fm[strings.ToLower(strings.TrimPrefix(name, prefix))] = value
+	}
+	l.layers = append(l.layers, layer{name: EnvLayer, fm: fm})
+}
+
+// AddFlags adds a layer named FlagLayer holding the flags of fs that were
+// set on the command line, keyed by flag name. Flags left at their
+// defaults do not override earlier layers. Fs must already be parsed.
+func (l *Layers) AddFlags(fs *flag.FlagSet) {
+	fm := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		fm[f.Name] = f.Value.String()
+	})
+	l.layers = append(l.layers, layer{name: FlagLayer, fm: fm})
+}
+
+// Get returns the effective value of key and the name of the layer it came
+// from. If no layer has the key, ok is false.
+func (l *Layers) Get(key string) (value, source string, ok bool) {
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		if v, ok := l.layers[i].fm[key]; ok {
+			return v, l.layers[i].name, true
+		}
+	}
+	return "", "", false
+}
+
+// Map returns the effective values of all keys.
+func (l *Layers) Map() map[string]string {
+	fm := map[string]string{}
+	for _, ly := range l.layers {
+		for k, v := range ly.fm {
+			fm[k] = v
+		}
+	}
+	return fm
+}
+
+// Sources returns the name of the layer each effective value came from,
+// keyed by key.
+func (l *Layers) Sources() map[string]string {
+	sources := map[string]string{}
+	for _, ly := range l.layers {
+		for k := range ly.fm {
+			sources[k] = ly.name
+		}
+	}
+	return sources
+}
+
+// Unmarshal decodes the effective values into the struct pointed to by v.
+// See Unmarshal.
+func (l *Layers) Unmarshal(v interface{}) error {
+	return Unmarshal(l.Map(), v)
+}
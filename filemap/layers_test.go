@@ -0,0 +1,95 @@
+/*
+NAME
+  layers_test.go - tests for layers.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  layers_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLayers tests precedence and sources of layered values.
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "netsender.conf")
+	err := os.WriteFile(file, []byte("mp 60\nmo paused\nip A0\n"), defaultPerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_MO", "normal")
+	t.Setenv("TEST_VS", "2")
+	t.Setenv("OTHER_MP", "1")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("vs", "1", "")
+	fs.String("ip", "A1", "")
+	err = fs.Parse([]string{"-vs", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var l Layers
+	l.AddMap("defaults", map[string]string{"mp": "30", "mo": "normal", "dk": "0"})
+	err = l.AddFile(file, "\n", " ")
+	if err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	err = l.AddFile(filepath.Join(dir, "missing.conf"), "\n", " ")
+	if err != nil {
+		t.Errorf("AddFile of missing file failed: %v", err)
+	}
+	l.AddEnv("TEST_")
+	l.AddFlags(fs)
+
+	tests := []struct {
+		key, value, source string
+	}{
+		{"dk", "0", "defaults"},
+		{"mp", "60", file},
+		{"ip", "A0", file}, // The unset flag does not override.
+		{"mo", "normal", EnvLayer},
+		{"vs", "3", FlagLayer},
+	}
+	for _, test := range tests {
+		value, source, ok := l.Get(test.key)
+		if !ok || value != test.value || source != test.source {
+			t.Errorf("Get(%q) = %q, %q, %v, want %q, %q", test.key, value, source, ok, test.value, test.source)
+		}
+	}
+	if _, _, ok := l.Get("xx"); ok {
+		t.Errorf("Get of missing key returned ok")
+	}
+
+	want := map[string]string{"dk": "0", "mp": "60", "ip": "A0", "mo": "normal", "vs": "3"}
+	if got := l.Map(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Map() = %v, want %v", got, want)
+	}
+	wantSources := map[string]string{"dk": "defaults", "mp": file, "ip": file, "mo": EnvLayer, "vs": FlagLayer}
+	if got := l.Sources(); !reflect.DeepEqual(got, wantSources) {
+		t.Errorf("Sources() = %v, want %v", got, wantSources)
+	}
+}
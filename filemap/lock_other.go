@@ -0,0 +1,45 @@
+//go:build !unix
+// +build !unix
+
+/*
+NAME
+  lock_other.go - advisory file locking for platforms without flock.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  lock_other.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import "sync"
+
+// locks serialises updates within the process where flock is unavailable.
+var locks sync.Map
+
+// lock takes an exclusive lock on file within the current process only,
+// blocking until the lock is available. The returned function releases the
+// lock.
+func lock(file string) (func() error, error) {
+	mu, _ := locks.LoadOrStore(file, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return func() error {
+		mu.(*sync.Mutex).Unlock()
+		return nil
+	}, nil
+}
@@ -0,0 +1,57 @@
+//go:build unix
+// +build unix
+
+/*
+NAME
+  lock_unix.go - advisory file locking using flock.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  lock_unix.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive advisory lock on file, creating it if necessary,
+// blocking until the lock is available. The returned function releases the
+// lock.
+func lock(file string) (func() error, error) {
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, defaultPerm)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		return f.Close()
+	}, nil
+}
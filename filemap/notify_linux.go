@@ -0,0 +1,83 @@
+/*
+NAME
+  notify_linux.go - change notification using inotify.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  notify_linux.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// notifyMask selects the inotify events that may change the content of a
+// file within the watched directory.
+const notifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_CREATE |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// notify watches directory dir using inotify, signalling on the returned
+// channel whenever the file called name within it may have changed.
+// Signals are coalesced, so a slow receiver sees at most one pending
+// signal. The channel is closed once the returned Closer is closed.
+func notify(dir, name string) (io.Closer, <-chan struct{}, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, nil, os.NewSyscallError("inotify_init1", err)
+	}
+	_, err = syscall.InotifyAddWatch(fd, dir, notifyMask)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	// A non-blocking descriptor uses the runtime poller, so that closing
+	// the file interrupts a pending read.
+	f := os.NewFile(uintptr(fd), "inotify")
+	signals := make(chan struct{}, 1)
+	go func() {
+		defer close(signals)
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+				ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+				off += syscall.SizeofInotifyEvent
+				evName := strings.TrimRight(string(buf[off:off+int(ev.Len)]), "\x00")
+				off += int(ev.Len)
+				if evName != name && ev.Mask&syscall.IN_Q_OVERFLOW == 0 {
+					continue
+				}
+				select {
+				case signals <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return f, signals, nil
+}
@@ -0,0 +1,38 @@
+//go:build !linux
+// +build !linux
+
+/*
+NAME
+  notify_other.go - change notification for platforms without inotify.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  notify_other.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"errors"
+	"io"
+)
+
+// notify is not supported on this platform, so watchers poll instead.
+func notify(dir, name string) (io.Closer, <-chan struct{}, error) {
+	return nil, nil, errors.New("change notification not supported")
+}
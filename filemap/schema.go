@@ -0,0 +1,364 @@
+/*
+NAME
+  schema.go - declarative schemas for validating, documenting and
+  migrating maps.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  schema.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors reported by Schema.Validate and Schema.Migrate.
+var (
+	ErrRequired   = errors.New("required key is missing or empty")
+	ErrOutOfRange = errors.New("value is out of range")
+	ErrUnknownKey = errors.New("key is not in schema")
+	ErrVersion    = errors.New("version is newer than schema")
+	ErrRename     = errors.New("rename target is already in use")
+)
+
+// Type is the type of the value of a Field.
+type Type int
+
+// Field types.
+const (
+	TypeString Type = iota
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeDuration
+	TypeList // A delimited list of strings.
+)
+
+// goTypes are the Go types used to decode values of each Type.
+var goTypes = map[Type]reflect.Type{
+	TypeString:   reflect.TypeOf(""),
+	TypeInt:      reflect.TypeOf(int64(0)),
+	TypeFloat:    reflect.TypeOf(float64(0)),
+	TypeBool:     reflect.TypeOf(false),
+	TypeDuration: reflect.TypeOf(time.Duration(0)),
+	TypeList:     reflect.TypeOf([]string(nil)),
+}
+
+func (t Type) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeDuration:
+		return "duration"
+	case TypeList:
+		return "list"
+	}
+	return "unknown"
+}
+
+// Field describes a key of a Schema.
+type Field struct {
+	Key         string
+	Type        Type
+	Values      []string // Permitted values, or list elements; nil permits any.
+	Min, Max    string   // Inclusive bounds for int, float and duration values; empty for none.
+	Delim       string   // Separates list elements; a comma if empty.
+	Required    bool
+	Default     string
+	Description string
+}
+
+// Migration transforms a map from the previous version of a Schema to
+// Version. Keys are renamed first and Transform, if not nil, is then
+// applied to the renamed map. Renames are applied together, reading each
+// old key from the map as it was before the migration, so they may be
+// chained or swap keys. It is an error to rename a key onto one that is
+// present and not itself renamed, or to rename two keys onto one.
+type Migration struct {
+	Version   int               // The version produced by the migration.
+	Rename    map[string]string // Keys to rename, from old to new.
+	Transform func(fm map[string]string) error
+}
+
+// Schema describes the keys of a map, for example:
+//
+//	s := filemap.Schema{
+//		Version:    2,
+//		VersionKey: "sv",
+//		Fields: []filemap.Field{
+//			{Key: "mp", Type: filemap.TypeDuration, Min: "1s", Default: "1m", Description: "Monitor period."},
+//			{Key: "mo", Type: filemap.TypeString, Values: []string{"normal", "paused"}, Default: "normal"},
+//			{Key: "dk", Type: filemap.TypeInt, Required: true, Description: "Device key."},
+//		},
+//		Migrations: []filemap.Migration{{Version: 2, Rename: map[string]string{"period": "mp"}}},
+//	}
+type Schema struct {
+	Version      int    // The current version of the schema.
+	VersionKey   string // The key holding the version of a map, if versioned.
+	Fields       []Field
+	Migrations   []Migration // Migrations to each version, in increasing order.
+	AllowUnknown bool        // Indicates keys not in the schema are permitted.
+}
+
+// Validate checks the whole of map fm against the schema, returning every
+// violation found, ordered by field and then by unknown key, or nil if
+// there are none. A missing or empty value violates a required field and
+// is otherwise permitted.
+func (s *Schema) Validate(fm map[string]string) KeyErrors {
+	var errs KeyErrors
+	known := map[string]bool{s.VersionKey: s.VersionKey != ""}
+	for _, f := range s.Fields {
+		known[f.Key] = true
+		value := fm[f.Key]
+		if value == "" {
+			if f.Required {
+				errs = append(errs, &KeyError{Key: f.Key, Err: ErrRequired})
+			}
+			continue
+		}
+		err := f.check(value)
+		if err != nil {
+			errs = append(errs, &KeyError{Key: f.Key, Value: value, Err: err})
+		}
+	}
+
+	if !s.AllowUnknown {
+		var unknown []string
+		for k := range fm {
+			if !known[k] {
+				unknown = append(unknown, k)
+			}
+		}
+		sort.Strings(unknown)
+		for _, k := range unknown {
+			errs = append(errs, &KeyError{Key: k, Value: fm[k], Err: ErrUnknownKey})
+		}
+	}
+	return errs
+}
+
+// delim returns the delimiter of list elements.
+func (f *Field) delim() string {
+	if f.Delim == "" {
+		return defaultDelim
+	}
+	return f.Delim
+}
+
+// check checks a non-empty value against the field.
+func (f *Field) check(value string) error {
+	v, err := f.parse(value)
+	if err != nil {
+		return err
+	}
+	ft := fieldTag{key: f.Key, enum: f.Values, delim: f.delim()}
+	err = ft.validate(value, f.Type == TypeList)
+	if err != nil {
+		return err
+	}
+
+	for _, bound := range []struct {
+		s    string
+		sign int
+	}{{f.Min, -1}, {f.Max, 1}} {
+		if bound.s == "" || f.Type == TypeList {
+			continue
+		}
+		b, err := f.parse(bound.s)
+		if err != nil {
+			return fmt.Errorf("invalid schema bound %q: %w", bound.s, err)
+		}
+		if compare(v, b) == bound.sign {
+			return fmt.Errorf("%w [%s, %s]", ErrOutOfRange, f.Min, f.Max)
+		}
+	}
+	return nil
+}
+
+// parse decodes value according to the field's type.
+func (f *Field) parse(value string) (reflect.Value, error) {
+	t, ok := goTypes[f.Type]
+	if !ok {
+		return reflect.Value{}, ErrUnsupportedType
+	}
+	v := reflect.New(t).Elem()
+	return v, decode(v, value, f.delim())
+}
+
+// compare returns -1, 0 or 1 as numeric value a is less than, equal to or
+// greater than b, which are of the same type.
+func compare(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int64:
+		switch {
+		case a.Int() < b.Int():
+			return -1
+		case a.Int() > b.Int():
+			return 1
+		}
+	case reflect.Float64:
+		switch {
+		case a.Float() < b.Float():
+			return -1
+		case a.Float() > b.Float():
+			return 1
+		}
+	}
+	return 0
+}
+
+// Defaults returns a map of the fields with defaults, suitable as the first
+// of a set of Layers.
+func (s *Schema) Defaults() map[string]string {
+	fm := map[string]string{}
+	for _, f := range s.Fields {
+		if f.Default != "" {
+			fm[f.Key] = f.Default
+		}
+	}
+	return fm
+}
+
+// Template renders a commented template of the schema using the given
+// delimiters, suitable for reading with ReadFile, Layers.AddFile or Watch,
+// which ignore comments, but not with ReadFrom. Each field is preceded
+// by comments holding its description and constraints, and is set to its
+// default.
+func (s *Schema) Template(major, minor string) string {
+	var items []string
+	if s.VersionKey != "" {
+		items = append(items, commentPrefix+" Schema version.", s.VersionKey+minor+strconv.Itoa(s.Version), "")
+	}
+	for _, f := range s.Fields {
+		if f.Description != "" {
+			items = append(items, commentPrefix+" "+f.Description)
+		}
+		constraints := []string{"type " + f.Type.String()}
+		if f.Values != nil {
+			constraints = append(constraints, "one of "+strings.Join(f.Values, "|"))
+		}
+		if f.Type != TypeList {
+			if f.Min != "" {
+				constraints = append(constraints, "min "+f.Min)
+			}
+			if f.Max != "" {
+				constraints = append(constraints, "max "+f.Max)
+			}
+		}
+		if f.Required {
+			constraints = append(constraints, "required")
+		}
+		items = append(items,
+			commentPrefix+" "+strings.Join(constraints, ", ")+".",
+			escapeKey(f.Key, major, minor)+minor+escape(f.Default, major, minor),
+			"",
+		)
+	}
+	return strings.Join(items, major)
+}
+
+// Migrate returns a copy of fm migrated from its version, held under the
+// VersionKey, to the version of the schema by applying each later
+// migration in turn. A map without a version is treated as version 0. It
+// is an error for the map to be newer than the schema.
+func (s *Schema) Migrate(fm map[string]string) (map[string]string, error) {
+	if s.VersionKey == "" {
+		return nil, errors.New("schema has no version key")
+	}
+	version := 0
+	if v, ok := fm[s.VersionKey]; ok {
+		var err error
+		version, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, &KeyError{Key: s.VersionKey, Value: v, Err: err}
+		}
+	}
+	if version > s.Version {
+		return nil, &KeyError{Key: s.VersionKey, Value: fm[s.VersionKey], Err: ErrVersion}
+	}
+
+	migrated := make(map[string]string, len(fm))
+	for k, v := range fm {
+		migrated[k] = v
+	}
+	for _, m := range s.Migrations {
+		if m.Version <= version || m.Version > s.Version {
+			continue
+		}
+		err := rename(migrated, m.Rename)
+		if err != nil {
+			return nil, fmt.Errorf("migration to version %d: %w", m.Version, err)
+		}
+		if m.Transform != nil {
+			err = m.Transform(migrated)
+			if err != nil {
+				return nil, fmt.Errorf("migration to version %d: %w", m.Version, err)
+			}
+		}
+	}
+	migrated[s.VersionKey] = strconv.Itoa(s.Version)
+	return migrated, nil
+}
+
+// rename renames the keys of fm in place according to renames, taking
+// every value from a snapshot of fm so that the result does not depend on
+// the order in which the renames are visited.
+func rename(fm, renames map[string]string) error {
+	snapshot := make(map[string]string, len(renames))
+	targets := make(map[string]string, len(renames))
+	for from, to := range renames {
+		v, ok := fm[from]
+		if !ok {
+			continue
+		}
+		if _, moved := renames[to]; !moved {
+			if cur, ok := fm[to]; ok {
+				return &KeyError{Key: to, Value: cur, Err: fmt.Errorf("renaming %q: %w", from, ErrRename)}
+			}
+		}
+		if other, ok := targets[to]; ok {
+			if other > from {
+				other, from = from, other
+			}
+			return &KeyError{Key: to, Err: fmt.Errorf("renaming %q and %q: %w", other, from, ErrRename)}
+		}
+		snapshot[from] = v
+		targets[to] = from
+	}
+	for from := range snapshot {
+		delete(fm, from)
+	}
+	for to, from := range targets {
+		fm[to] = snapshot[from]
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+/*
+NAME
+  schema_test.go - tests for schema.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  schema_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testSchema = Schema{
+	Version:    2,
+	VersionKey: "sv",
+	Fields: []Field{
+		{Key: "mp", Type: TypeDuration, Min: "1s", Max: "1h", Default: "1m", Description: "Monitor period."},
+		{Key: "mo", Type: TypeString, Values: []string{"normal", "paused"}, Default: "normal"},
+		{Key: "dk", Type: TypeInt, Required: true, Description: "Device key."},
+		{Key: "gn", Type: TypeFloat, Min: "0", Max: "1"},
+		{Key: "ip", Type: TypeList, Values: []string{"A0", "A1", "D0"}},
+	},
+	Migrations: []Migration{
+		{Version: 1, Rename: map[string]string{"period": "mp"}},
+		{Version: 2, Transform: func(fm map[string]string) error {
+			if fm["mo"] == "off" {
+				fm["mo"] = "paused"
+			}
+			delete(fm, "legacy")
+			return nil
+		}},
+	},
+}
+
+// TestValidate tests that all violations are reported.
+func TestValidate(t *testing.T) {
+	good := map[string]string{"sv": "2", "mp": "10s", "mo": "paused", "dk": "123", "gn": "0.5", "ip": "A0,D0"}
+	if errs := testSchema.Validate(good); errs != nil {
+		t.Errorf("unexpected violations: %v", errs)
+	}
+
+	bad := map[string]string{"mp": "2h", "mo": "off", "gn": "high", "ip": "A0,B7", "xx": "1"}
+	errs := testSchema.Validate(bad)
+	want := []struct {
+		key string
+		err error
+	}{
+		{"mp", ErrOutOfRange},
+		{"mo", ErrInvalidValue},
+		{"dk", ErrRequired},
+		{"gn", nil},
+		{"ip", ErrInvalidValue},
+		{"xx", ErrUnknownKey},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d violations, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if errs[i].Key != w.key || (w.err != nil && !errors.Is(errs[i], w.err)) {
+			t.Errorf("violation %d: got %v, want key %q with %v", i, errs[i], w.key, w.err)
+		}
+	}
+
+	s := testSchema
+	s.AllowUnknown = true
+	if errs := s.Validate(map[string]string{"dk": "1", "xx": "1"}); errs != nil {
+		t.Errorf("unexpected violations with unknown keys allowed: %v", errs)
+	}
+}
+
+// TestTemplate tests that templates document the schema and parse to the
+// defaults.
+func TestTemplate(t *testing.T) {
+	tmpl := testSchema.Template("\n", " ")
+	for _, want := range []string{
+		"# Monitor period.\n# type duration, min 1s, max 1h.\nmp 1m\n",
+		"# type string, one of normal|paused.\nmo normal\n",
+		"# Device key.\n# type int, required.\ndk \n",
+	} {
+		if !strings.Contains(tmpl, want) {
+			t.Errorf("template does not contain %q:\n%s", want, tmpl)
+		}
+	}
+
+	got := Parse(tmpl, "\n", " ").Map()
+	want := testSchema.Defaults()
+	want["sv"], want["dk"], want["gn"], want["ip"] = "2", "", "", ""
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("template parsed to %v, want %v", got, want)
+	}
+}
+
+// TestTemplateLayers tests that a template read as a layer holds only the
+// keys of the schema.
+func TestTemplateLayers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "template.conf")
+	err := os.WriteFile(file, []byte(testSchema.Template("\n", " ")), defaultPerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var l Layers
+	err = l.AddFile(file, "\n", " ")
+	if err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	l.AddMap("device", map[string]string{"dk": "1"}) // Supply the required key.
+	if errs := testSchema.Validate(l.Map()); errs != nil {
+		t.Errorf("unexpected violations: %v", errs)
+	}
+}
+
+// TestMigrate tests migrating maps between versions.
+func TestMigrate(t *testing.T) {
+	old := map[string]string{"period": "5s", "mo": "off", "legacy": "x", "dk": "1"}
+	got, err := testSchema.Migrate(old)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	want := map[string]string{"sv": "2", "mp": "5s", "mo": "paused", "dk": "1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, ok := old["mp"]; ok {
+		t.Errorf("Migrate modified its argument")
+	}
+
+	// Only later migrations are applied.
+	got, err = testSchema.Migrate(map[string]string{"sv": "1", "period": "5s", "mo": "off"})
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	want = map[string]string{"sv": "2", "period": "5s", "mo": "paused"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	_, err = testSchema.Migrate(map[string]string{"sv": "3"})
+	if !errors.Is(err, ErrVersion) {
+		t.Errorf("got error %v, want %v", err, ErrVersion)
+	}
+}
+
+// TestMigrateRename tests that renames do not depend on map order and do
+// not overwrite keys.
+func TestMigrateRename(t *testing.T) {
+	tests := []struct {
+		name   string
+		rename map[string]string
+		in     map[string]string
+		want   map[string]string
+	}{
+		{
+			name:   "chain",
+			rename: map[string]string{"a": "b", "b": "c"},
+			in:     map[string]string{"a": "A", "b": "B"},
+			want:   map[string]string{"sv": "1", "b": "A", "c": "B"},
+		},
+		{
+			name:   "swap",
+			rename: map[string]string{"a": "b", "b": "a"},
+			in:     map[string]string{"a": "A", "b": "B", "x": "X"},
+			want:   map[string]string{"sv": "1", "a": "B", "b": "A", "x": "X"},
+		},
+		{
+			name:   "partial chain",
+			rename: map[string]string{"a": "b", "b": "c"},
+			in:     map[string]string{"b": "B"},
+			want:   map[string]string{"sv": "1", "c": "B"},
+		},
+	}
+	for _, test := range tests {
+		s := Schema{Version: 1, VersionKey: "sv", Migrations: []Migration{{Version: 1, Rename: test.rename}}}
+		// Repeat to exercise different map iteration orders.
+		for i := 0; i < 50; i++ {
+			got, err := s.Migrate(test.in)
+			if err != nil {
+				t.Fatalf("%s: Migrate failed: %v", test.name, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("%s: got %v, want %v", test.name, got, test.want)
+			}
+		}
+	}
+
+	for _, rename := range []map[string]string{
+		{"a": "b"},
+		{"a": "c", "b": "c"},
+	} {
+		s := Schema{Version: 1, VersionKey: "sv", Migrations: []Migration{{Version: 1, Rename: rename}}}
+		_, err := s.Migrate(map[string]string{"a": "A", "b": "B"})
+		if !errors.Is(err, ErrRename) {
+			t.Errorf("rename %v: got error %v, want %v", rename, err, ErrRename)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+/*
+NAME
+  watch.go - watching of map files for changes.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  watch.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Watch timing defaults.
+const (
+	debounceDelay = 100 * time.Millisecond // Quiet period after a change before the file is read.
+	pollInterval  = time.Second            // Interval between checks when polling.
+)
+
+// Event reports a change to a watched file. Map holds the new content of
+// the file, which is empty if the file has been removed. If the file could
+// not be read, Err is set and the other fields are empty.
+type Event struct {
+	Changes ChangeSet
+	Map     map[string]string
+	Err     error
+}
+
+// Watcher watches a map file for changes. Events are delivered on C, which
+// is closed when the Watcher is closed.
+type Watcher struct {
+	C <-chan Event
+
+	file         string
+	major, minor string
+	debounce     time.Duration
+	poll         time.Duration
+	c            chan Event
+	done         chan struct{}
+	closer       io.Closer // Stops change notifications, if any.
+	last         map[string]string
+	once         sync.Once
+	wg           sync.WaitGroup
+}
+
+// Watch watches file for changes, sending an Event with the changed keys
+// whenever its content changes. Rapid successive writes are debounced
+// into a single Event. The directory of file is watched, rather than the
+// file itself, so editors that save by renaming a new file over the old
+// one are handled. On Linux, inotify is used to detect changes, otherwise
+// the file is polled. The file need not exist when Watch is called. The
+// file is read with ReadFile, so comments are ignored.
+func Watch(file, major, minor string) (*Watcher, error) {
+	return watch(file, major, minor, debounceDelay, pollInterval, true)
+}
+
+// watch implements Watch, allowing the timing to be set and inotify to be
+// disabled.
+func watch(file, major, minor string, debounce, poll time.Duration, useNotify bool) (*Watcher, error) {
+	// Start watching before the first read, so that no change is missed.
+	var closer io.Closer
+	var signals <-chan struct{}
+	if useNotify {
+		var err error
+		closer, signals, err = notify(filepath.Dir(file), filepath.Base(file))
+		if err != nil {
+			signals = nil // Fall back to polling.
+		}
+	}
+	info, _ := os.Stat(file)
+
+	fm, err := readMap(file, major, minor)
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, err
+	}
+	c := make(chan Event)
+	w := &Watcher{
+		C:        c,
+		file:     file,
+		major:    major,
+		minor:    minor,
+		debounce: debounce,
+		poll:     poll,
+		c:        c,
+		done:     make(chan struct{}),
+		closer:   closer,
+		last:     fm,
+	}
+	w.wg.Add(1)
+	go w.run(signals, info)
+	return w, nil
+}
+
+// Close stops watching and closes C.
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		if w.closer != nil {
+			err = w.closer.Close()
+		}
+		w.wg.Wait()
+	})
+	return err
+}
+
+// run waits for signals of changes, or polls if signals is nil, and checks
+// the file once changes have settled. Info describes the file as first read.
+func (w *Watcher) run(signals <-chan struct{}, info os.FileInfo) {
+	defer w.wg.Done()
+	defer close(w.c)
+
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	startPolling := func() {
+		ticker = time.NewTicker(w.poll)
+		tick = ticker.C
+	}
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	if signals == nil {
+		startPolling()
+	}
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case _, ok := <-signals:
+			if !ok {
+				// Notification failed, so poll instead.
+				signals = nil
+				startPolling()
+			}
+			timer.Reset(w.debounce)
+		case <-tick:
+			newInfo, _ := os.Stat(w.file)
+			if modified(info, newInfo) {
+				timer.Reset(w.debounce)
+			}
+			info = newInfo
+		case <-timer.C:
+			if !w.check() {
+				return
+			}
+		}
+	}
+}
+
+// check reads the file and sends an Event if its content has changed. It
+// returns false if the Watcher was closed while sending.
+func (w *Watcher) check() bool {
+	var ev Event
+	fm, err := readMap(w.file, w.major, w.minor)
+	if err != nil {
+		ev.Err = err
+	} else {
+		ev.Changes = Diff(w.last, fm)
+		if len(ev.Changes) == 0 {
+			return true
+		}
+		ev.Map = fm
+		w.last = fm
+	}
+	select {
+	case w.c <- ev:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// modified returns true if the file described by b differs from that
+// described by a. Either may be nil if the file did not exist.
+func modified(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a != b
+	}
+	return !os.SameFile(a, b) || !a.ModTime().Equal(b.ModTime()) || a.Size() != b.Size()
+}
+
+// readMap reads the map stored in file with ReadFile, ignoring comments,
+// and returns an empty map if the file does not exist.
+func readMap(file, major, minor string) (map[string]string, error) {
+	f, err := ReadFile(file, major, minor)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f.Map(), nil
+}
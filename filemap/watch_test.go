@@ -0,0 +1,112 @@
+/*
+NAME
+  watch_test.go - tests for watch.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  watch_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestWatch tests watching with inotify, where available, and polling.
+func TestWatch(t *testing.T) {
+	for _, notify := range []bool{true, false} {
+		t.Run("notify="+strconv.FormatBool(notify), func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "tags.conf")
+			w, err := watch(file, "\n", " ", 50*time.Millisecond, 10*time.Millisecond, notify)
+			if err != nil {
+				t.Fatalf("watch failed: %v", err)
+			}
+			defer w.Close()
+
+			// Creation by an atomic rename.
+			err = WriteFile(file, []byte("a 1\nb 2\n"), defaultPerm, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ev := nextEvent(t, w)
+			want := ChangeSet{{Op: Added, Key: "a", New: "1"}, {Op: Added, Key: "b", New: "2"}}
+			if !reflect.DeepEqual(ev.Changes, want) {
+				t.Errorf("got changes %v, want %v", ev.Changes, want)
+			}
+
+			// Rapid writes in place are debounced into one event.
+			for i := 0; i < 5; i++ {
+				err = os.WriteFile(file, []byte("a 1\nb "+strconv.Itoa(i+3)+"\n"), defaultPerm)
+				if err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			ev = nextEvent(t, w)
+			want = ChangeSet{{Op: Changed, Key: "b", Old: "2", New: "7"}}
+			if !reflect.DeepEqual(ev.Changes, want) {
+				t.Errorf("got changes %v, want %v", ev.Changes, want)
+			}
+			if !reflect.DeepEqual(ev.Map, map[string]string{"a": "1", "b": "7"}) {
+				t.Errorf("got map %v", ev.Map)
+			}
+
+			// Removal.
+			err = os.Remove(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ev = nextEvent(t, w)
+			want = ChangeSet{{Op: Removed, Key: "a", Old: "1"}, {Op: Removed, Key: "b", Old: "7"}}
+			if !reflect.DeepEqual(ev.Changes, want) {
+				t.Errorf("got changes %v, want %v", ev.Changes, want)
+			}
+
+			// Closing closes the channel.
+			w.Close()
+			select {
+			case _, ok := <-w.C:
+				if ok {
+					t.Errorf("unexpected event after Close")
+				}
+			case <-time.After(time.Second):
+				t.Errorf("channel not closed after Close")
+			}
+		})
+	}
+}
+
+func nextEvent(t *testing.T, w *Watcher) Event {
+	t.Helper()
+	select {
+	case ev := <-w.C:
+		if ev.Err != nil {
+			t.Fatalf("unexpected error: %v", ev.Err)
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
@@ -0,0 +1,160 @@
+/*
+NAME
+  write.go - atomic, crash-safe writing and locked updating of files.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  write.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// BackupSuffix is appended to the name of a file to name its backup.
+const BackupSuffix = ".bak"
+
+// defaultPerm is the permission of files created by this package.
+const defaultPerm = 0644
+
+// WriteFile writes data to file atomically, so that a crash or power loss
+// leaves either the previous or the new content, never a mix. The data is
+// written to a temporary file in the same directory, synced and renamed over
+// file. If file is a symbolic link, its target is replaced and the link
+// kept. An existing file keeps its permissions, otherwise perm is used, but
+// its ownership is not preserved: the new file is owned by the writer. If
+// backup is true, the previous content of file, if any, is kept in a file
+// with the BackupSuffix appended to its name.
+func WriteFile(file string, data []byte, perm os.FileMode, backup bool) error {
+	resolved, err := filepath.EvalSymlinks(file)
+	switch {
+	case err == nil:
+		file = resolved
+	case !os.IsNotExist(err):
+		return err
+	}
+	if info, err := os.Stat(file); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(file)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed.
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if backup {
+		err = backupFile(file)
+		if err != nil {
+			return err
+		}
+	}
+	err = os.Rename(tmp.Name(), file)
+	if err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// backupFile replaces the backup of file with its current content. It is
+// not an error if file does not exist.
+func backupFile(file string) error {
+	bak := file + BackupSuffix
+	err := os.Remove(bak)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// Hard link where possible, so that the backup costs nothing.
+	err = os.Link(file, bak)
+	if err == nil || os.IsNotExist(err) {
+		return nil
+	}
+
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultPerm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// syncDir syncs the directory dir so that a rename within it is durable.
+// Directories that cannot be synced, e.g. on some platforms, are ignored.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	d.Sync()
+	return d.Close()
+}
+
+// Update performs a locked read-modify-write of the map stored in file.
+// The file is read into a File, or an empty File if it does not exist,
+// which is passed to fn. If fn returns nil, the File is written back using
+// WriteFile, otherwise the file is left unchanged and the error returned.
+//
+// An advisory lock, held on a file with ".lock" appended to the name of
+// file, prevents concurrent updates by processes using Update.
+func Update(file, major, minor string, backup bool, fn func(f *File) error) error {
+	unlock, err := lock(file + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := ReadFile(file, major, minor)
+	if os.IsNotExist(err) {
+		f, err = Parse("", major, minor), nil
+	}
+	if err != nil {
+		return err
+	}
+	err = fn(f)
+	if err != nil {
+		return err
+	}
+	return WriteFile(file, []byte(f.String()), defaultPerm, backup)
+}
@@ -0,0 +1,159 @@
+/*
+NAME
+  write_test.go - tests for write.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  write_test.go is Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package filemap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestWriteFile tests atomic writing, permissions and backups.
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "conf")
+
+	err := WriteFile(file, []byte("a 1\n"), 0600, true)
+	if err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := os.Stat(file + BackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("unexpected backup of new file: %v", err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got permissions %v, want 0600", info.Mode().Perm())
+	}
+
+	// Overwriting keeps the permissions and backs up the previous content.
+	err = WriteFile(file, []byte("a 2\n"), 0644, true)
+	if err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	checkContent(t, file, "a 2\n")
+	checkContent(t, file+BackupSuffix, "a 1\n")
+	info, err = os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got permissions %v, want 0600", info.Mode().Perm())
+	}
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %d files, want 2", len(entries))
+	}
+}
+
+// TestWriteFileSymlink tests that writing through a symbolic link replaces
+// its target and keeps the link.
+func TestWriteFileSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.conf")
+	link := filepath.Join(dir, "link.conf")
+	err := os.WriteFile(target, []byte("a 1\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink("target.conf", link)
+	if err != nil {
+		t.Skipf("symbolic links not supported: %v", err)
+	}
+
+	err = WriteFile(link, []byte("a 2\n"), defaultPerm, true)
+	if err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("link replaced by a regular file")
+	}
+	checkContent(t, target, "a 2\n")
+	checkContent(t, target+BackupSuffix, "a 1\n")
+}
+
+// TestUpdate tests that concurrent updates are serialised.
+func TestUpdate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "conf")
+	const n = 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(file, "\n", " ", false, func(f *File) error {
+				v, _ := f.Get("count")
+				count, _ := strconv.Atoi(v)
+				f.Set("count", strconv.Itoa(count+1))
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Update failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	checkContent(t, file, "count "+strconv.Itoa(n)+"\n")
+
+	// An error from fn leaves the file unchanged.
+	errAbort := errors.New("abort")
+	err := Update(file, "\n", " ", true, func(f *File) error {
+		f.Set("count", "0")
+		return errAbort
+	})
+	if err != errAbort {
+		t.Errorf("got error %v, want %v", err, errAbort)
+	}
+	checkContent(t, file, "count "+strconv.Itoa(n)+"\n")
+	if _, err := os.Stat(file + BackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("unexpected backup after aborted update: %v", err)
+	}
+}
+
+func checkContent(t *testing.T, file, want string) {
+	t.Helper()
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("%s: got %q, want %q", filepath.Base(file), got, want)
+	}
+}
@@ -0,0 +1,306 @@
+/*
+NAME
+  concurrent.go
+
+DESCRIPTION
+  concurrent.go provides a ConcurrentWriteCloser that duplicates writes to
+  multiple io.WriteClosers, each written by its own goroutine from its own
+  bounded queue, so that a slow destination does not delay the others.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ausocean/utils/ring"
+)
+
+// Errors returned by ConcurrentWriteCloser.
+var (
+	ErrClosed       = errors.New("ioext: write to closed writer")
+	ErrDropped      = errors.New("ioext: write dropped by full queue")
+	ErrDrainTimeout = errors.New("ioext: timed out draining queue")
+)
+
+// DefaultQueue is the queue capacity, in writes, of a Destination that does
+// not specify one.
+const DefaultQueue = 16
+
+// nextTimeout is how long a ring-backed destination waits for data before
+// checking again.
+const nextTimeout = time.Second
+
+// Policy determines what happens to a write when a destination's queue is
+// full. The zero value is DropOldest, so that by default a stalled
+// destination loses its oldest writes rather than delaying the others.
+type Policy int
+
+// Queue policies.
+//
+// Block makes Write wait for space in the full queue, which holds up the
+// writes to every other destination until the stalled destination catches
+// up or the writer is closed. It should only be used where losing writes is
+// worse than delaying all destinations.
+const (
+	DropOldest Policy = iota // Drop the oldest queued write to make room.
+	DropNewest               // Drop the new write.
+	Block                    // Wait for space in the queue, delaying all destinations.
+)
+
+// Destination describes a destination of a ConcurrentWriteCloser.
+//
+// W must allow Close to be called while a Write is in progress, since a
+// destination that is not drained in time is closed without waiting for its
+// writing goroutine, which is usually what unblocks a stalled Write.
+type Destination struct {
+	W      io.WriteCloser
+	Queue  int    // Capacity of the queue in writes; DefaultQueue if zero.
+	Policy Policy // What to do when the queue is full; DropOldest if zero.
+
+	// Ring, if not nil, queues writes in a ring buffer instead of a
+	// channel, in which case Queue and Policy are ignored and writes are
+	// dropped according to the ring buffer's timeout.
+	Ring *ring.Buffer
+}
+
+// ConcurrentWriteCloser is an io.WriteCloser that duplicates its writes to
+// all of its destinations, similar to the Unix tee(1) command, like
+// MultiWriteCloser. Unlike MultiWriteCloser, each destination is written by
+// its own goroutine from its own bounded queue, so Write returns once the
+// data is queued and a stalled destination delays only itself. Errors from
+// the destinations, and dropped writes, are returned by the next Write or
+// by Close.
+type ConcurrentWriteCloser struct {
+	dests   []*destination
+	drain   time.Duration
+	closing chan struct{} // Closed by Close to abandon blocked writes.
+	once    sync.Once
+	mu      sync.Mutex
+	closed  bool
+}
+
+// destination is a Destination with its queue and writing goroutine.
+type destination struct {
+	Destination
+	q         chan []byte
+	done      chan struct{}
+	index     int         // The index of the destination, for errors.
+	abandoned atomic.Bool // Indicates remaining queued writes are to be discarded.
+	mu        sync.Mutex
+	errs      MultiError
+}
+
+// NewConcurrentWriteCloser returns a ConcurrentWriteCloser writing to the
+// given destinations. Close waits at most drain for the queues to empty.
+func NewConcurrentWriteCloser(drain time.Duration, dests ...Destination) *ConcurrentWriteCloser {
+	c := &ConcurrentWriteCloser{drain: drain, closing: make(chan struct{})}
+	for i, d := range dests {
+		if d.Queue <= 0 {
+			d.Queue = DefaultQueue
+		}
+		dst := &destination{Destination: d, index: i, done: make(chan struct{})}
+		if d.Ring == nil {
+			dst.q = make(chan []byte, d.Queue)
+			go dst.run()
+		} else {
+			go dst.runRing()
+		}
+		c.dests = append(c.dests, dst)
+	}
+	return c
+}
+
+// Write implements io.Writer, queuing a copy of p for each destination.
+// It returns len(p) unless the writer is closed. A Write blocked by a full
+// queue returns ErrClosed for that destination if the writer is closed.
+func (c *ConcurrentWriteCloser) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, ErrClosed
+	}
+	select {
+	case <-c.closing:
+		return 0, ErrClosed
+	default:
+	}
+
+	errs := c.collect()
+	buf := append([]byte(nil), p...)
+	for _, d := range c.dests {
+		errs.add(d.index, d.W, d.put(buf, c.closing))
+	}
+	if len(errs) == 0 {
+		return len(p), nil
+	}
+	return len(p), errs
+}
+
+// collect returns the errors of the destinations since last collected.
+func (c *ConcurrentWriteCloser) collect() MultiError {
+	var errs MultiError
+	for _, d := range c.dests {
+		d.mu.Lock()
+		errs = append(errs, d.errs...)
+		d.errs = nil
+		d.mu.Unlock()
+	}
+	return errs
+}
+
+// Close stops accepting writes and waits up to the drain timeout for the
+// queues to be written, then closes all of the destinations. Destinations
+// not drained in time report ErrDrainTimeout and their remaining queued
+// writes are discarded.
+func (c *ConcurrentWriteCloser) Close() error {
+	// Release any Write blocked on a full queue, which holds the lock.
+	c.once.Do(func() { close(c.closing) })
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	for _, d := range c.dests {
+		if d.Ring != nil {
+			d.Ring.Close()
+		} else {
+			close(d.q)
+		}
+	}
+
+	var errs MultiError
+	timer := time.NewTimer(c.drain)
+	defer timer.Stop()
+	expired := false
+	for _, d := range c.dests {
+		if !expired {
+			select {
+			case <-d.done:
+				continue
+			case <-timer.C:
+				expired = true
+			}
+		}
+		select {
+		case <-d.done:
+		default:
+			d.abandoned.Store(true)
+			errs.add(d.index, d.W, ErrDrainTimeout)
+		}
+	}
+
+	for _, d := range c.dests {
+		errs.add(d.index, d.W, d.W.Close())
+	}
+	errs = append(c.collect(), errs...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// put queues p according to the destination's policy, giving up on a
+// blocked write once closing is closed.
+func (d *destination) put(p []byte, closing <-chan struct{}) error {
+	if d.Ring != nil {
+		_, err := d.Ring.Write(p)
+		d.Ring.Flush()
+		return err
+	}
+
+	select {
+	case d.q <- p:
+		return nil
+	default:
+	}
+	switch d.Policy {
+	case DropNewest:
+		return ErrDropped
+	case Block:
+		select {
+		case d.q <- p:
+			return nil
+		case <-closing:
+			return ErrClosed
+		}
+	default:
+		// Only the consumer competes for the queue, so the send succeeds.
+		var err error
+		select {
+		case <-d.q:
+			err = ErrDropped
+		default:
+		}
+		d.q <- p
+		return err
+	}
+}
+
+// run writes queued data to the destination until the queue is closed.
+func (d *destination) run() {
+	defer close(d.done)
+	for p := range d.q {
+		if d.abandoned.Load() {
+			continue
+		}
+		_, err := write(d.W, p)
+		d.record(err)
+	}
+}
+
+// runRing writes data queued in the ring buffer to the destination until
+// the ring buffer is closed.
+func (d *destination) runRing() {
+	defer close(d.done)
+	for {
+		chunk, err := d.Ring.Next(nextTimeout)
+		switch err {
+		case nil:
+		case ring.ErrTimeout:
+			continue
+		default:
+			return
+		}
+		if !d.abandoned.Load() {
+			_, err = chunk.WriteTo(d.W)
+			d.record(err)
+		}
+		chunk.Close()
+	}
+}
+
+// record retains a write error to be returned later.
+func (d *destination) record(err error) {
+	if err == nil {
+		return
+	}
+	d.mu.Lock()
+	d.errs.add(d.index, d.W, err)
+	d.mu.Unlock()
+}
@@ -0,0 +1,244 @@
+/*
+NAME
+  concurrent_test.go
+
+DESCRIPTION
+  concurrent_test.go provides testing functionality for the
+  ConcurrentWriteCloser found in concurrent.go.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ausocean/utils/ring"
+)
+
+// syncWriteCloser is a testWriteCloser that is safe for concurrent use and
+// that can be stalled until released.
+type syncWriteCloser struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	closed  bool
+	release chan struct{} // If not nil, writes block until closed.
+}
+
+func (w *syncWriteCloser) Write(p []byte) (int, error) {
+	if w.release != nil {
+		<-w.release
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *syncWriteCloser) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *syncWriteCloser) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// waitFor waits for w to hold want.
+func waitFor(t *testing.T, w *syncWriteCloser, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for w.String() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %q, want %q", w.String(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestConcurrentStalled checks that a stalled destination does not delay
+// the others, and that Close gives up on it after the drain timeout.
+func TestConcurrentStalled(t *testing.T) {
+	fast := &syncWriteCloser{}
+	slow := &syncWriteCloser{release: make(chan struct{})}
+	defer close(slow.release)
+	c := NewConcurrentWriteCloser(50*time.Millisecond, Destination{W: slow}, Destination{W: fast, Queue: 4 * DefaultQueue})
+
+	for _, s := range []string{"a", "b", "c"} {
+		n, err := c.Write([]byte(s))
+		if n != 1 || err != nil {
+			t.Fatalf("Write returned %d, %v", n, err)
+		}
+	}
+	waitFor(t, fast, "abc")
+
+	// By default, a full queue drops writes rather than blocking the others.
+	var dropped bool
+	for i := 0; i < DefaultQueue+2; i++ {
+		_, err := c.Write([]byte("x"))
+		if errors.Is(err, ErrDropped) {
+			dropped = true
+		}
+	}
+	if !dropped {
+		t.Errorf("expected writes to the stalled destination to be dropped")
+	}
+	waitFor(t, fast, "abc"+strings.Repeat("x", DefaultQueue+2))
+
+	start := time.Now()
+	err := c.Close()
+	if !errors.Is(err, ErrDrainTimeout) {
+		t.Errorf("got error %v from Close, want %v", err, ErrDrainTimeout)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Close took %v", d)
+	}
+	if !fast.closed || !slow.closed {
+		t.Errorf("destinations not closed")
+	}
+	if _, err := c.Write([]byte("d")); err != ErrClosed {
+		t.Errorf("got error %v after Close, want %v", err, ErrClosed)
+	}
+}
+
+// TestConcurrentBlockedClose checks that Close is not held up by a Write
+// blocked on the full queue of a stalled destination with the Block policy.
+func TestConcurrentBlockedClose(t *testing.T) {
+	w := &syncWriteCloser{release: make(chan struct{})}
+	defer close(w.release)
+	c := NewConcurrentWriteCloser(50*time.Millisecond, Destination{W: w, Queue: 1, Policy: Block})
+
+	// Stall the writer on "a" with "b" queued, so that writing "c" blocks.
+	c.Write([]byte("a"))
+	for len(c.dests[0].q) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Write([]byte("b"))
+	blocked := make(chan error)
+	go func() {
+		_, err := c.Write([]byte("c"))
+		blocked <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	closed := make(chan error)
+	go func() { closed <- c.Close() }()
+	select {
+	case err := <-closed:
+		if !errors.Is(err, ErrDrainTimeout) {
+			t.Errorf("got error %v from Close, want %v", err, ErrDrainTimeout)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked by stalled Write")
+	}
+	if err := <-blocked; !errors.Is(err, ErrClosed) {
+		t.Errorf("got error %v from blocked Write, want %v", err, ErrClosed)
+	}
+	if !w.closed {
+		t.Errorf("destination not closed")
+	}
+}
+
+// TestConcurrentPolicies checks the handling of full queues.
+func TestConcurrentPolicies(t *testing.T) {
+	tests := []struct {
+		policy  Policy
+		want    string
+		dropped int
+	}{
+		{policy: DropNewest, want: "ab", dropped: 3},
+		{policy: DropOldest, want: "ae", dropped: 3},
+		{policy: Block, want: "abcde"},
+	}
+	for _, test := range tests {
+		w := &syncWriteCloser{release: make(chan struct{})}
+		c := NewConcurrentWriteCloser(time.Second, Destination{W: w, Queue: 1, Policy: test.policy})
+
+		// Stall the writer on "a" with "b" queued, before further writes.
+		c.Write([]byte("a"))
+		for len(c.dests[0].q) != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		c.Write([]byte("b"))
+
+		dropped := 0
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for _, s := range []string{"c", "d", "e"} {
+				_, err := c.Write([]byte(s))
+				if errors.Is(err, ErrDropped) {
+					dropped++
+				}
+			}
+		}()
+		if test.policy != Block {
+			<-done
+		}
+		close(w.release)
+		<-done
+
+		err := c.Close()
+		if err != nil {
+			t.Errorf("policy %d: unexpected error from Close: %v", test.policy, err)
+		}
+		if w.String() != test.want || dropped != test.dropped {
+			t.Errorf("policy %d: got %q with %d dropped, want %q with %d dropped", test.policy, w.String(), dropped, test.want, test.dropped)
+		}
+	}
+}
+
+// TestConcurrentRing checks a destination backed by a ring buffer.
+func TestConcurrentRing(t *testing.T) {
+	w := &syncWriteCloser{}
+	rb := ring.NewBuffer(4, 16, time.Second)
+	c := NewConcurrentWriteCloser(time.Second, Destination{W: w, Ring: rb})
+
+	for _, s := range []string{"one", "two", "three"} {
+		_, err := c.Write([]byte(s))
+		if err != nil {
+			t.Fatalf("unexpected error from Write: %v", err)
+		}
+	}
+	err := c.Close()
+	if err != nil {
+		t.Errorf("unexpected error from Close: %v", err)
+	}
+	if w.String() != "onetwothree" {
+		t.Errorf("got %q, want %q", w.String(), "onetwothree")
+	}
+
+	// Writes too long for the ring buffer are reported.
+	c = NewConcurrentWriteCloser(time.Second, Destination{W: &syncWriteCloser{}, Ring: ring.NewBuffer(1, 2, time.Second)})
+	_, err = c.Write([]byte("long"))
+	if !errors.Is(err, ring.ErrTooLong) {
+		t.Errorf("got error %v, want %v", err, ring.ErrTooLong)
+	}
+	c.Close()
+}
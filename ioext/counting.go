@@ -0,0 +1,333 @@
+/*
+NAME
+  counting.go
+
+DESCRIPTION
+  counting.go provides io.Reader and io.Writer wrappers that count the bytes
+  passing through them, mirror writes to a secondary writer, or report
+  progress.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CountingWriter is an io.Writer that counts the bytes written to an
+// underlying io.Writer. The count may be read concurrently with writes.
+type CountingWriter struct {
+	w io.Writer
+	n atomic.Int64
+}
+
+// NewCountingWriter returns a CountingWriter writing to w.
+func NewCountingWriter(w io.Writer) *CountingWriter {
+	return &CountingWriter{w: w}
+}
+
+// Write implements io.Writer.
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// Count returns the number of bytes written.
+func (c *CountingWriter) Count() int64 { return c.n.Load() }
+
+// Reset sets the count to zero, returning the previous count.
+func (c *CountingWriter) Reset() int64 { return c.n.Swap(0) }
+
+// CountingReader is an io.Reader that counts the bytes read from an
+// underlying io.Reader. The count may be read concurrently with reads.
+type CountingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+// NewCountingReader returns a CountingReader reading from r.
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{r: r}
+}
+
+// Read implements io.Reader.
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// Count returns the number of bytes read.
+func (c *CountingReader) Count() int64 { return c.n.Load() }
+
+// Reset sets the count to zero, returning the previous count.
+func (c *CountingReader) Reset() int64 { return c.n.Swap(0) }
+
+// TeeWriteCloser is an io.WriteCloser that writes to a primary
+// io.WriteCloser and mirrors what was written to a secondary io.Writer.
+// Unlike MultiWriteCloser, failures of the secondary never fail the
+// primary.
+//
+// The first secondary error stops mirroring, and nothing more is written
+// to the secondary, even if the error was transient, until Resume is
+// called. The error is available from SecondaryErr, so callers should
+// check it periodically, e.g. to reconnect a network secondary and then
+// call Resume.
+type TeeWriteCloser struct {
+	primary   io.WriteCloser
+	secondary io.Writer
+	mu        sync.Mutex
+	err       error
+}
+
+// NewTeeWriteCloser returns a TeeWriteCloser writing to primary and
+// mirroring to secondary.
+func NewTeeWriteCloser(primary io.WriteCloser, secondary io.Writer) *TeeWriteCloser {
+	return &TeeWriteCloser{primary: primary, secondary: secondary}
+}
+
+// Write implements io.Writer, returning the result of writing to the
+// primary. The bytes accepted by the primary are then mirrored.
+func (t *TeeWriteCloser) Write(p []byte) (int, error) {
+	n, err := t.primary.Write(p)
+	if n > 0 {
+		t.mu.Lock()
+		if t.err == nil {
+			_, t.err = write(t.secondary, p[:n])
+		}
+		t.mu.Unlock()
+	}
+	return n, err
+}
+
+// Close closes the primary and, if it is an io.Closer, the secondary. Only
+// the primary's error is returned; a secondary error is available from
+// SecondaryErr.
+func (t *TeeWriteCloser) Close() error {
+	err := t.primary.Close()
+	if c, ok := t.secondary.(io.Closer); ok {
+		cerr := c.Close()
+		t.mu.Lock()
+		if t.err == nil {
+			t.err = cerr
+		}
+		t.mu.Unlock()
+	}
+	return err
+}
+
+// SecondaryErr returns the first error from the secondary, if any.
+func (t *TeeWriteCloser) SecondaryErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// Resume clears the secondary error, if any, so that mirroring resumes with
+// the next write, and returns the cleared error. Bytes written while
+// mirroring was stopped are not mirrored.
+func (t *TeeWriteCloser) Resume() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	err := t.err
+	t.err = nil
+	return err
+}
+
+// Progress describes the progress of a transfer.
+type Progress struct {
+	Bytes   int64         // Bytes transferred so far.
+	Total   int64         // Expected total bytes, or zero if unknown.
+	Elapsed time.Duration // Time since the transfer started.
+}
+
+// Rate returns the average rate of the transfer in bytes per second.
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Bytes) / p.Elapsed.Seconds()
+}
+
+// ProgressFunc is called to report progress.
+type ProgressFunc func(Progress)
+
+// progress tracks a transfer and decides when to report it. If an interval
+// is set, a goroutine reports on the interval even while no I/O occurs,
+// until stop is called.
+type progress struct {
+	mu       sync.Mutex
+	total    int64
+	every    int64         // Report after this many bytes; zero for never.
+	interval time.Duration // Report after this long; zero for never.
+	fn       ProgressFunc
+	clock    clock
+	start    time.Time
+	bytes    int64
+	lastN    int64     // Bytes at the last report.
+	lastT    time.Time // Time of the last report.
+	once     sync.Once
+	done     chan struct{} // Closed by stop to end the interval goroutine.
+	stopped  chan struct{} // Closed when the interval goroutine returns.
+}
+
+func newProgress(total, every int64, interval time.Duration, fn ProgressFunc, c clock) *progress {
+	now := c.Now()
+	p := &progress{
+		total:    total,
+		every:    every,
+		interval: interval,
+		fn:       fn,
+		clock:    c,
+		start:    now,
+		lastT:    now,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	if interval > 0 {
+		go p.tick()
+	} else {
+		close(p.stopped)
+	}
+	return p
+}
+
+// tick reports each time interval elapses without a report, until stop is
+// called.
+func (p *progress) tick() {
+	defer close(p.stopped)
+	for {
+		p.mu.Lock()
+		wait := p.lastT.Add(p.interval).Sub(p.clock.Now())
+		p.mu.Unlock()
+		if wait > 0 {
+			select {
+			case <-p.clock.After(wait):
+			case <-p.done:
+				return
+			}
+		}
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+		p.add(0, false)
+	}
+}
+
+// stop ends interval reporting, waiting for any report in progress from
+// the interval goroutine to return. It may be called more than once.
+func (p *progress) stop() {
+	p.once.Do(func() { close(p.done) })
+	<-p.stopped
+}
+
+// add records n more bytes, reporting if due or if final is true.
+func (p *progress) add(n int, final bool) {
+	p.mu.Lock()
+	p.bytes += int64(n)
+	now := p.clock.Now()
+	due := final ||
+		(p.every > 0 && p.bytes-p.lastN >= p.every) ||
+		(p.interval > 0 && now.Sub(p.lastT) >= p.interval)
+	if !due {
+		p.mu.Unlock()
+		return
+	}
+	p.lastN, p.lastT = p.bytes, now
+	pr := Progress{Bytes: p.bytes, Total: p.total, Elapsed: now.Sub(p.start)}
+	p.mu.Unlock()
+	p.fn(pr)
+}
+
+// ProgressWriter is an io.Writer that reports the progress of writes to an
+// underlying io.Writer. Progress is reported after a write once every bytes
+// have been written since the last report, and whenever interval elapses
+// without a report, including while the transfer is stalled; a zero value
+// disables either trigger. Interval reports are made from a separate
+// goroutine, so fn may be called concurrently with Write, and continue
+// until Stop is called.
+type ProgressWriter struct {
+	w io.Writer
+	p *progress
+}
+
+// NewProgressWriter returns a ProgressWriter writing to w and calling fn to
+// report progress. Total is the expected size of the transfer, or zero if
+// unknown. If interval is non-zero, Stop must be called when the transfer
+// ends.
+func NewProgressWriter(w io.Writer, total, every int64, interval time.Duration, fn ProgressFunc) *ProgressWriter {
+	return &ProgressWriter{w: w, p: newProgress(total, every, interval, fn, realClock{})}
+}
+
+// Write implements io.Writer.
+func (pw *ProgressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.p.add(n, false)
+	return n, err
+}
+
+// Report reports the progress immediately, e.g. at the end of a transfer.
+// It is safe to call concurrently with Write.
+func (pw *ProgressWriter) Report() { pw.p.add(0, true) }
+
+// Stop stops interval reports. No interval report is made once Stop
+// returns. It may be called more than once.
+func (pw *ProgressWriter) Stop() { pw.p.stop() }
+
+// ProgressReader is an io.Reader that reports the progress of reads from an
+// underlying io.Reader, as for ProgressWriter. Progress is also reported
+// when the underlying reader returns io.EOF, which stops interval reports.
+type ProgressReader struct {
+	r io.Reader
+	p *progress
+}
+
+// NewProgressReader returns a ProgressReader reading from r and calling fn
+// to report progress. Total is the expected size of the transfer, or zero
+// if unknown. If interval is non-zero, Stop must be called if the transfer
+// ends before io.EOF is read.
+func NewProgressReader(r io.Reader, total, every int64, interval time.Duration, fn ProgressFunc) *ProgressReader {
+	return &ProgressReader{r: r, p: newProgress(total, every, interval, fn, realClock{})}
+}
+
+// Read implements io.Reader.
+func (pr *ProgressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if err == io.EOF {
+		pr.p.stop()
+	}
+	pr.p.add(n, err == io.EOF)
+	return n, err
+}
+
+// Report reports the progress immediately. It is safe to call concurrently
+// with Read.
+func (pr *ProgressReader) Report() { pr.p.add(0, true) }
+
+// Stop stops interval reports, as for ProgressWriter.Stop.
+func (pr *ProgressReader) Stop() { pr.p.stop() }
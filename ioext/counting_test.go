@@ -0,0 +1,235 @@
+/*
+NAME
+  counting_test.go
+
+DESCRIPTION
+  counting_test.go provides testing functionality for the counting, tee and
+  progress wrappers found in counting.go.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCounting checks counting of bytes written and read concurrently.
+func TestCounting(t *testing.T) {
+	cw := NewCountingWriter(io.Discard)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cw.Write(make([]byte, 10))
+			}
+		}()
+	}
+	wg.Wait()
+	if got := cw.Reset(); got != 8000 {
+		t.Errorf("got count %d, want 8000", got)
+	}
+	if got := cw.Count(); got != 0 {
+		t.Errorf("got count %d after Reset, want 0", got)
+	}
+
+	cr := NewCountingReader(strings.NewReader("hello, world"))
+	io.ReadAll(cr)
+	if got := cr.Count(); got != 12 {
+		t.Errorf("got count %d, want 12", got)
+	}
+}
+
+// TestTeeWriteCloser checks that the secondary is mirrored, that its
+// failure does not fail the primary, and that mirroring can be resumed.
+func TestTeeWriteCloser(t *testing.T) {
+	primary := &testWriteCloser{}
+	secondary := &testWriteCloser{}
+	tee := NewTeeWriteCloser(primary, secondary)
+
+	tee.Write([]byte("ab"))
+	secondary.failOnWrite = true
+	n, err := tee.Write([]byte("cd"))
+	if n != 2 || err != nil {
+		t.Errorf("Write returned %d, %v despite secondary failure", n, err)
+	}
+	secondary.failOnWrite = false
+	tee.Write([]byte("ef"))
+
+	if string(primary.buf) != "abcdef" || string(secondary.buf) != "ab" {
+		t.Errorf("got %q and %q, want abcdef and ab", primary.buf, secondary.buf)
+	}
+	if tee.SecondaryErr() == nil {
+		t.Errorf("expected secondary error")
+	}
+
+	// Mirroring resumes once the error is cleared.
+	if tee.Resume() == nil {
+		t.Errorf("expected Resume to return the secondary error")
+	}
+	tee.Write([]byte("gh"))
+	if string(secondary.buf) != "abgh" || tee.SecondaryErr() != nil {
+		t.Errorf("got %q and %v after Resume, want abgh and no error", secondary.buf, tee.SecondaryErr())
+	}
+
+	err = tee.Close()
+	if err != nil {
+		t.Errorf("unexpected error from Close: %v", err)
+	}
+	if !primary.closed || !secondary.closed {
+		t.Errorf("writers not closed")
+	}
+
+	// Primary errors are returned.
+	tee = NewTeeWriteCloser(&testWriteCloser{failOnWrite: true}, &bytes.Buffer{})
+	if _, err := tee.Write([]byte("ab")); err == nil {
+		t.Errorf("expected error from failing primary")
+	}
+}
+
+// TestProgress checks reporting by bytes using a fake clock.
+func TestProgress(t *testing.T) {
+	c := newFakeClock()
+	var reports []Progress
+	report := func(p Progress) { reports = append(reports, p) }
+
+	// By bytes.
+	pw := &ProgressWriter{w: io.Discard, p: newProgress(100, 25, 0, report, c)}
+	for i := 0; i < 10; i++ {
+		c.Advance(time.Second)
+		pw.Write(make([]byte, 10))
+	}
+	want := []Progress{
+		{Bytes: 30, Total: 100, Elapsed: 3 * time.Second},
+		{Bytes: 60, Total: 100, Elapsed: 6 * time.Second},
+		{Bytes: 90, Total: 100, Elapsed: 9 * time.Second},
+	}
+	if !reflect.DeepEqual(reports, want) {
+		t.Errorf("got reports %+v, want %+v", reports, want)
+	}
+	if rate := reports[0].Rate(); rate != 10 {
+		t.Errorf("got rate %v, want 10", rate)
+	}
+	pw.Report()
+	if last := reports[len(reports)-1]; last.Bytes != 100 {
+		t.Errorf("got final report %+v, want 100 bytes", last)
+	}
+
+	// Reading, with a final report at EOF.
+	c = newFakeClock()
+	reports = nil
+	r := &slowReader{r: strings.NewReader(strings.Repeat("x", 50)), c: c}
+	pr := &ProgressReader{r: r, p: newProgress(0, 20, 0, report, c)}
+	buf := make([]byte, 10)
+	for {
+		_, err := pr.Read(buf)
+		if err != nil {
+			break
+		}
+	}
+	want = []Progress{
+		{Bytes: 20, Elapsed: 2 * time.Second},
+		{Bytes: 40, Elapsed: 4 * time.Second},
+		{Bytes: 50, Elapsed: 6 * time.Second},
+	}
+	if !reflect.DeepEqual(reports, want) {
+		t.Errorf("got reports %+v, want %+v", reports, want)
+	}
+}
+
+// TestProgressStalled checks that interval reports are made while a
+// transfer is stalled, and end when stopped.
+func TestProgressStalled(t *testing.T) {
+	c := newFakeClock()
+	reports := make(chan Progress, 10)
+	pw := &ProgressWriter{w: io.Discard, p: newProgress(0, 0, 2*time.Second, func(p Progress) { reports <- p }, c)}
+
+	pw.Write(make([]byte, 10))
+	for i := 1; i <= 3; i++ {
+		c.waitForTimer()
+		c.Advance(2 * time.Second)
+		select {
+		case p := <-reports:
+			want := Progress{Bytes: 10, Elapsed: time.Duration(i) * 2 * time.Second}
+			if p != want {
+				t.Errorf("got report %+v, want %+v", p, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no report %d while stalled", i)
+		}
+	}
+
+	// Bytes written between reports are included in the next.
+	c.waitForTimer()
+	c.Advance(time.Second)
+	pw.Write(make([]byte, 10))
+	c.Advance(time.Second)
+	select {
+	case p := <-reports:
+		want := Progress{Bytes: 20, Elapsed: 8 * time.Second}
+		if p != want {
+			t.Errorf("got report %+v, want %+v", p, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no report after write")
+	}
+
+	pw.Stop()
+	pw.Stop()
+	c.Advance(time.Minute)
+	select {
+	case p := <-reports:
+		t.Errorf("got report %+v after Stop", p)
+	default:
+	}
+
+	// Reading to EOF makes a final report and stops interval reports.
+	pr := &ProgressReader{r: strings.NewReader("x"), p: newProgress(0, 0, 2*time.Second, func(p Progress) { reports <- p }, c)}
+	io.ReadAll(pr)
+	if p := <-reports; p.Bytes != 1 {
+		t.Errorf("got final report %+v, want 1 byte", p)
+	}
+	c.Advance(time.Minute)
+	select {
+	case p := <-reports:
+		t.Errorf("got report %+v after EOF", p)
+	default:
+	}
+}
+
+// slowReader advances a fake clock by a second on each read.
+type slowReader struct {
+	r io.Reader
+	c *fakeClock
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	s.c.Advance(time.Second)
+	return s.r.Read(p)
+}
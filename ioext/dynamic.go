@@ -0,0 +1,209 @@
+/*
+NAME
+  dynamic.go
+
+DESCRIPTION
+  dynamic.go provides a DynamicWriteCloser that duplicates writes to a set of
+  io.WriteClosers that may change while in use, evicting writers that fail
+  repeatedly.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"io"
+	"sync"
+)
+
+// WriterStats holds statistics for a writer of a DynamicWriteCloser.
+type WriterStats struct {
+	Bytes       int64 // Total bytes written.
+	Errors      int   // Total write errors.
+	Consecutive int   // Write errors since the last successful write.
+	LastErr     error // The most recent write error.
+}
+
+// EvictFunc is called with a writer evicted from a DynamicWriteCloser and
+// the write error that caused its eviction.
+type EvictFunc func(w io.WriteCloser, err error)
+
+// DynamicWriteCloser is an io.WriteCloser that duplicates its writes to a
+// set of io.WriteClosers, like MultiWriteCloser, but writers may be added
+// and removed while it is in use. Writers are identified by equality, so
+// must be comparable, e.g. pointers.
+//
+// If eviction is enabled with SetEviction, a writer that fails a number of
+// consecutive writes is closed and removed, so that a permanently broken
+// writer does not cause every later write to fail.
+type DynamicWriteCloser struct {
+	mu      sync.Mutex
+	writers []*member
+	maxErrs int
+	onEvict EvictFunc
+}
+
+// member is a writer of a DynamicWriteCloser with its statistics.
+type member struct {
+	w     io.WriteCloser
+	index int // The index of the writer at the last write.
+	stats WriterStats
+}
+
+// NewDynamicWriteCloser returns a DynamicWriteCloser initially writing to
+// the given writers, with eviction disabled.
+func NewDynamicWriteCloser(writers ...io.WriteCloser) *DynamicWriteCloser {
+	d := &DynamicWriteCloser{}
+	for _, w := range writers {
+		d.Add(w)
+	}
+	return d
+}
+
+// Add adds w to the writers, unless it is already present.
+func (d *DynamicWriteCloser) Add(w io.WriteCloser) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.find(w) < 0 {
+		d.writers = append(d.writers, &member{w: w})
+	}
+}
+
+// Remove removes w from the writers without closing it, returning false if
+// it was not present.
+func (d *DynamicWriteCloser) Remove(w io.WriteCloser) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	i := d.find(w)
+	if i < 0 {
+		return false
+	}
+	d.writers = append(d.writers[:i], d.writers[i+1:]...)
+	return true
+}
+
+// find returns the index of w in the writers, or -1 if not present.
+func (d *DynamicWriteCloser) find(w io.WriteCloser) int {
+	for i, m := range d.writers {
+		if m.w == w {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetEviction evicts writers after n consecutive write errors, closing them
+// and calling fn, if not nil. Eviction is disabled if n is zero or less.
+func (d *DynamicWriteCloser) SetEviction(n int, fn EvictFunc) {
+	d.mu.Lock()
+	d.maxErrs = n
+	d.onEvict = fn
+	d.mu.Unlock()
+}
+
+// Writers returns the current writers in the order they were added.
+func (d *DynamicWriteCloser) Writers() []io.WriteCloser {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	writers := make([]io.WriteCloser, len(d.writers))
+	for i, m := range d.writers {
+		writers[i] = m.w
+	}
+	return writers
+}
+
+// Stats returns the statistics of w, or false if w is not present.
+func (d *DynamicWriteCloser) Stats(w io.WriteCloser) (WriterStats, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	i := d.find(w)
+	if i < 0 {
+		return WriterStats{}, false
+	}
+	return d.writers[i].stats, true
+}
+
+// Write implements io.Writer, writing p to each writer in turn. Errors are
+// retained and returned together, as for MultiWriteCloser, including any
+// from closing evicted writers.
+func (d *DynamicWriteCloser) Write(p []byte) (int, error) {
+	var (
+		errs    MultiError
+		evicted []*member
+	)
+	d.mu.Lock()
+	writers := d.writers[:0]
+	for i, m := range d.writers {
+		n, err := write(m.w, p)
+		m.index = i
+		m.stats.Bytes += int64(n)
+		if err != nil {
+			errs.add(i, m.w, err)
+			m.stats.Errors++
+			m.stats.Consecutive++
+			m.stats.LastErr = err
+		} else {
+			m.stats.Consecutive = 0
+		}
+		if d.maxErrs > 0 && m.stats.Consecutive >= d.maxErrs {
+			evicted = append(evicted, m)
+			continue
+		}
+		writers = append(writers, m)
+	}
+	// Clear evicted writers from the tail of the reused slice.
+	for i := len(writers); i < len(d.writers); i++ {
+		d.writers[i] = nil
+	}
+	d.writers = writers
+	onEvict := d.onEvict
+	d.mu.Unlock()
+
+	// The callback is called without the lock held so that it may add or
+	// remove writers.
+	for _, m := range evicted {
+		errs.add(m.index, m.w, m.w.Close())
+		if onEvict != nil {
+			onEvict(m.w, m.stats.LastErr)
+		}
+	}
+	if len(errs) == 0 {
+		return len(p), nil
+	}
+	return len(p), errs
+}
+
+// Close calls Close on all of the writers and removes them.
+func (d *DynamicWriteCloser) Close() error {
+	d.mu.Lock()
+	writers := d.writers
+	d.writers = nil
+	d.mu.Unlock()
+
+	var errs MultiError
+	for i, m := range writers {
+		errs.add(i, m.w, m.w.Close())
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
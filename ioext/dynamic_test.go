@@ -0,0 +1,123 @@
+/*
+NAME
+  dynamic_test.go
+
+DESCRIPTION
+  dynamic_test.go provides testing functionality for the DynamicWriteCloser
+  found in dynamic.go.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"io"
+	"testing"
+)
+
+// TestDynamicAddRemove checks adding and removing writers in use.
+func TestDynamicAddRemove(t *testing.T) {
+	a, b := &testWriteCloser{}, &testWriteCloser{}
+	d := NewDynamicWriteCloser(a)
+
+	d.Write([]byte("1"))
+	d.Add(b)
+	d.Add(b) // Already present.
+	d.Write([]byte("2"))
+	if !d.Remove(a) {
+		t.Errorf("Remove returned false for present writer")
+	}
+	if d.Remove(a) {
+		t.Errorf("Remove returned true for absent writer")
+	}
+	d.Write([]byte("3"))
+
+	if string(a.buf) != "12" || string(b.buf) != "23" {
+		t.Errorf("got %q and %q, want 12 and 23", a.buf, b.buf)
+	}
+	if a.closed {
+		t.Errorf("removed writer was closed")
+	}
+	if got := d.Writers(); len(got) != 1 || got[0] != b {
+		t.Errorf("got writers %v, want only b", got)
+	}
+
+	err := d.Close()
+	if err != nil {
+		t.Errorf("unexpected error from Close: %v", err)
+	}
+	if !b.closed {
+		t.Errorf("writer not closed")
+	}
+}
+
+// TestDynamicEviction checks that failing writers are evicted and that
+// statistics are kept.
+func TestDynamicEviction(t *testing.T) {
+	good, bad := &testWriteCloser{}, &testWriteCloser{failOnWrite: true}
+	d := NewDynamicWriteCloser(good, bad)
+
+	var evicted []io.WriteCloser
+	d.SetEviction(3, func(w io.WriteCloser, err error) {
+		if err == nil {
+			t.Errorf("evicted without error")
+		}
+		evicted = append(evicted, w)
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := d.Write([]byte("ab"))
+		if err == nil {
+			t.Errorf("write %d: expected error from failing writer", i)
+		}
+	}
+	stats, ok := d.Stats(bad)
+	if !ok || stats.Errors != 2 || stats.Consecutive != 2 || stats.LastErr == nil {
+		t.Errorf("got stats %+v, %v for failing writer", stats, ok)
+	}
+
+	// A success resets the count of consecutive errors.
+	bad.failOnWrite = false
+	d.Write([]byte("ab"))
+	bad.failOnWrite = true
+	stats, _ = d.Stats(bad)
+	if stats.Consecutive != 0 || stats.Bytes != 2 {
+		t.Errorf("got stats %+v after success", stats)
+	}
+
+	for i := 0; i < 3; i++ {
+		d.Write([]byte("ab"))
+	}
+	if len(evicted) != 1 || evicted[0] != bad || !bad.closed {
+		t.Errorf("failing writer not evicted and closed: %v", evicted)
+	}
+	if _, ok := d.Stats(bad); ok {
+		t.Errorf("evicted writer still present")
+	}
+	_, err := d.Write([]byte("ab"))
+	if err != nil {
+		t.Errorf("unexpected error after eviction: %v", err)
+	}
+	stats, _ = d.Stats(good)
+	if stats.Bytes != 14 || stats.Errors != 0 {
+		t.Errorf("got stats %+v for good writer, want 14 bytes and no errors", stats)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+NAME
+  merge.go
+
+DESCRIPTION
+  merge.go provides a MergeReader that merges records from multiple sources
+  into a single stream ordered by key, such as a timestamp or sequence
+  number.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RecordReader reads records one at a time, returning io.EOF after the
+// last record.
+type RecordReader interface {
+	ReadRecord() ([]byte, error)
+}
+
+// lineReader is a RecordReader of lines.
+type lineReader struct {
+	s *bufio.Scanner
+}
+
+// NewLineReader returns a RecordReader reading lines from r, without their
+// line endings.
+func NewLineReader(r io.Reader) RecordReader {
+	return &lineReader{s: bufio.NewScanner(r)}
+}
+
+// ReadRecord implements RecordReader.
+func (l *lineReader) ReadRecord() ([]byte, error) {
+	if !l.s.Scan() {
+		if err := l.s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return append([]byte(nil), l.s.Bytes()...), nil
+}
+
+// KeyFunc returns the key by which a record is ordered, e.g. by parsing
+// its timestamp or sequence number.
+type KeyFunc[K any] func(record []byte) (K, error)
+
+// Record is a record read by a MergeReader.
+type Record[K any] struct {
+	Data   []byte
+	Key    K
+	Source int // The index of the source of the record.
+}
+
+// SourceError is an error from one of the sources of a MergeReader. If
+// Record is not nil, its key could not be determined and it was skipped,
+// otherwise the source failed and was dropped.
+type SourceError struct {
+	Source int
+	Record []byte
+	Err    error
+}
+
+func (e *SourceError) Error() string {
+	if e.Record != nil {
+		return fmt.Sprintf("source %d: record %q: %v", e.Source, e.Record, e.Err)
+	}
+	return fmt.Sprintf("source %d: %v", e.Source, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// MergeReader merges records from several sources into one stream in key
+// order, as determined by a less function. Each source is expected to be in order already, but records up to
+// lookahead-1 places out of order within a source are corrected by
+// buffering. Records with equal keys are returned in source order.
+//
+// A source that returns io.EOF is finished while the others continue. A
+// source that returns any other error is dropped, and the error is
+// returned once as a *SourceError, after which merging of the remaining
+// sources continues. Next returns io.EOF once all sources are finished.
+type MergeReader[K any] struct {
+	key       KeyFunc[K]
+	less      func(a, b K) bool
+	lookahead int
+	sources   []*mergeSource[K]
+	pending   []byte // Unread data of the current record for Read.
+}
+
+// mergeSource is a source of a MergeReader with its buffered records.
+type mergeSource[K any] struct {
+	r    RecordReader
+	buf  []Record[K] // Buffered records sorted by key.
+	done bool        // Indicates the source is finished or dropped.
+}
+
+// NewMergeReader returns a MergeReader merging records from sources ordered
+// by key, where less reports whether one key orders before another,
+// buffering up to lookahead records from each source. A lookahead less than
+// one is treated as one. For keys such as sequence numbers, less may be
+// cmp.Less; for timestamps, see NewTimeMergeReader.
+func NewMergeReader[K any](key KeyFunc[K], less func(a, b K) bool, lookahead int, sources ...RecordReader) *MergeReader[K] {
+	if lookahead < 1 {
+		lookahead = 1
+	}
+	m := &MergeReader[K]{key: key, less: less, lookahead: lookahead}
+	for _, r := range sources {
+		m.sources = append(m.sources, &mergeSource[K]{r: r})
+	}
+	return m
+}
+
+// NewTimeMergeReader returns a MergeReader merging records from sources
+// ordered by timestamp, as for NewMergeReader.
+func NewTimeMergeReader(key KeyFunc[time.Time], lookahead int, sources ...RecordReader) *MergeReader[time.Time] {
+	return NewMergeReader(key, time.Time.Before, lookahead, sources...)
+}
+
+// Next returns the record with the lowest key among those buffered from
+// all sources.
+func (m *MergeReader[K]) Next() (Record[K], error) {
+	for i := range m.sources {
+		err := m.fill(i)
+		if err != nil {
+			return Record[K]{}, err
+		}
+	}
+
+	var next *mergeSource[K]
+	for _, s := range m.sources {
+		if len(s.buf) != 0 && (next == nil || m.less(s.buf[0].Key, next.buf[0].Key)) {
+			next = s
+		}
+	}
+	if next == nil {
+		return Record[K]{}, io.EOF
+	}
+	rec := next.buf[0]
+	next.buf = next.buf[1:]
+	return rec, nil
+}
+
+// fill reads from source i until its buffer holds lookahead records or the
+// source is finished.
+func (m *MergeReader[K]) fill(i int) error {
+	s := m.sources[i]
+	for !s.done && len(s.buf) < m.lookahead {
+		data, err := s.r.ReadRecord()
+		if err == io.EOF {
+			s.done = true
+			break
+		}
+		if err != nil {
+			s.done = true
+			return &SourceError{Source: i, Err: err}
+		}
+		key, err := m.key(data)
+		if err != nil {
+			return &SourceError{Source: i, Record: data, Err: err}
+		}
+		s.insert(Record[K]{Data: data, Key: key, Source: i}, m.less)
+	}
+	return nil
+}
+
+// insert inserts rec into the buffer ordered by less, after any records
+// with equal keys.
+func (s *mergeSource[K]) insert(rec Record[K], less func(a, b K) bool) {
+	j := len(s.buf)
+	for j > 0 && less(rec.Key, s.buf[j-1].Key) {
+		j--
+	}
+	s.buf = append(s.buf, Record[K]{})
+	copy(s.buf[j+1:], s.buf[j:])
+	s.buf[j] = rec
+}
+
+// Read implements io.Reader, reading the merged records as lines, each
+// followed by a newline. Errors from sources are returned as by Next.
+func (m *MergeReader[K]) Read(p []byte) (int, error) {
+	if len(m.pending) == 0 {
+		rec, err := m.Next()
+		if err != nil {
+			return 0, err
+		}
+		m.pending = append(append(m.pending[:0], rec.Data...), '\n')
+	}
+	n := copy(p, m.pending)
+	m.pending = m.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,183 @@
+/*
+NAME
+  merge_test.go
+
+DESCRIPTION
+  merge_test.go provides testing functionality for the MergeReader found in
+  merge.go.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"cmp"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// secondKey returns the key of records of the form "<seconds> <text>".
+func secondKey(record []byte) (time.Time, error) {
+	f := strings.Fields(string(record))
+	if len(f) == 0 {
+		return time.Time{}, errors.New("empty record")
+	}
+	s, err := strconv.Atoi(f[0])
+	return time.Unix(int64(s), 0), err
+}
+
+// readAll reads all records from m, returning their data and any errors.
+func readAll[K any](m *MergeReader[K]) ([]string, []error) {
+	var (
+		recs []string
+		errs []error
+	)
+	for {
+		rec, err := m.Next()
+		if err == io.EOF {
+			return recs, errs
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recs = append(recs, string(rec.Data))
+	}
+}
+
+// TestMergeReader checks the ordering of merged records.
+func TestMergeReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		sources   []string
+		lookahead int
+		want      string
+	}{
+		{
+			name:    "interleaved",
+			sources: []string{"1 a\n4 a\n5 a\n", "2 b\n3 b\n6 b\n", ""},
+			want:    "1 a|2 b|3 b|4 a|5 a|6 b",
+		},
+		{
+			name:    "ties in source order",
+			sources: []string{"1 a\n2 a\n", "1 b\n2 b\n"},
+			want:    "1 a|1 b|2 a|2 b",
+		},
+		{
+			name:      "out of order within lookahead",
+			sources:   []string{"1 a\n3 a\n2 a\n4 a\n", "2 b\n"},
+			lookahead: 2,
+			want:      "1 a|2 a|2 b|3 a|4 a",
+		},
+	}
+	for _, test := range tests {
+		var sources []RecordReader
+		for _, s := range test.sources {
+			sources = append(sources, NewLineReader(strings.NewReader(s)))
+		}
+		recs, errs := readAll(NewTimeMergeReader(secondKey, test.lookahead, sources...))
+		if errs != nil {
+			t.Errorf("%s: unexpected errors: %v", test.name, errs)
+		}
+		if got := strings.Join(recs, "|"); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// failingReader returns records and then an error.
+type failingReader struct {
+	recs []string
+	err  error
+}
+
+func (r *failingReader) ReadRecord() ([]byte, error) {
+	if len(r.recs) == 0 {
+		return nil, r.err
+	}
+	rec := r.recs[0]
+	r.recs = r.recs[1:]
+	return []byte(rec), nil
+}
+
+// TestMergeReaderErrors checks that errors are reported per source and
+// that merging continues.
+func TestMergeReaderErrors(t *testing.T) {
+	errRead := errors.New("read failed")
+	m := NewTimeMergeReader(secondKey, 1,
+		NewLineReader(strings.NewReader("1 a\nbad\n3 a\n")),
+		&failingReader{recs: []string{"2 b"}, err: errRead},
+		NewLineReader(strings.NewReader("4 c\n")),
+	)
+	recs, errs := readAll(m)
+	if got, want := strings.Join(recs, "|"), "1 a|2 b|3 a|4 c"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want 2", errs)
+	}
+
+	var se *SourceError
+	if !errors.As(errs[0], &se) || se.Source != 0 || string(se.Record) != "bad" {
+		t.Errorf("unexpected key error: %v", errs[0])
+	}
+	if !errors.As(errs[1], &se) || se.Source != 1 || se.Record != nil || !errors.Is(errs[1], errRead) {
+		t.Errorf("unexpected read error: %v", errs[1])
+	}
+}
+
+// TestMergeReaderRead checks reading merged records as lines.
+func TestMergeReaderRead(t *testing.T) {
+	m := NewTimeMergeReader(secondKey, 1,
+		NewLineReader(strings.NewReader("1 a\n3 a\n")),
+		NewLineReader(strings.NewReader("2 b\n")),
+	)
+	got, err := io.ReadAll(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "1 a\n2 b\n3 a\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestMergeReaderSequence checks merging by a key other than a timestamp.
+func TestMergeReaderSequence(t *testing.T) {
+	seq := func(record []byte) (uint64, error) {
+		n, _, _ := strings.Cut(string(record), ":")
+		return strconv.ParseUint(n, 10, 64)
+	}
+	m := NewMergeReader(seq, cmp.Less[uint64], 1,
+		NewLineReader(strings.NewReader("1:a\n10:a\n")),
+		NewLineReader(strings.NewReader("2:b\n9:b\n11:b\n")),
+	)
+	recs, errs := readAll(m)
+	if errs != nil {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if got, want := strings.Join(recs, "|"), "1:a|2:b|9:b|10:a|11:b"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -36,22 +36,68 @@ package ioext
 import (
 	"fmt"
 	"io"
+	"strings"
 )
 
-// Errors is a collection of errors.
-type multiError []error
+// WriterError is an error from one of the writers of a multi-writer.
+type WriterError struct {
+	Index  int       // The index of the writer at the time of the error.
+	Writer io.Writer // The writer that failed.
+	Err    error     // The error returned by, or detected from, the writer.
+}
+
+func (e *WriterError) Error() string {
+	return fmt.Sprintf("writer %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *WriterError) Unwrap() error { return e.Err }
 
-func (e multiError) Error() string {
+// MultiError is a collection of errors from the writers of a multi-writer.
+// It implements Unwrap() []error, so errors.Is and errors.As match any of
+// the errors, e.g. errors.Is(err, io.ErrShortWrite), and errors.As with a
+// **WriterError finds the first failing writer.
+type MultiError []*WriterError
+
+func (e MultiError) Error() string {
 	if e == nil {
 		return "<nil>"
 	}
 	if len(e) == 0 {
 		return "<empty>"
 	}
-	if len(e) == 1 {
-		return e[0].Error()
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the individual errors.
+func (e MultiError) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// add appends err from the writer w at index i, if err is not nil.
+func (e *MultiError) add(i int, w io.Writer, err error) {
+	if err != nil {
+		*e = append(*e, &WriterError{Index: i, Writer: w, Err: err})
+	}
+}
+
+// write writes p to w, returning the number of bytes written and any
+// error, which is io.ErrShortWrite if fewer than len(p) bytes were written
+// without an error.
+func write(w io.Writer, p []byte) (int, error) {
+	n, err := w.Write(p)
+	if err == nil && n != len(p) {
+		err = io.ErrShortWrite
 	}
-	return fmt.Sprintf("%q", []error(e))
+	return n, err
 }
 
 // MultiWriteCloser creates an io.WriteCloser that duplicates its
@@ -60,21 +106,20 @@ func (e multiError) Error() string {
 // passed on to all of the provided io.WriteClosers.
 //
 // Each write is written to each listed writer, one at a time.
-// If a listed writer returns an error, that overall write operation
-// continues but the error is retained and returned in a []error.
-// Failures during close calls are treated the same way.
+// If a listed writer returns an error, or writes fewer bytes than
+// given, that overall write operation continues but the error is
+// retained and returned in a MultiError. Failures during close calls
+// are treated the same way.
 type multiWriteCloser struct {
 	writers []io.WriteCloser
 }
 
 // Write implements io.Writer.
 func (t *multiWriteCloser) Write(p []byte) (int, error) {
-	var err multiError
-	for _, w := range t.writers {
-		_, e := w.Write(p)
-		if e != nil {
-			err = append(err, e)
-		}
+	var err MultiError
+	for i, w := range t.writers {
+		_, e := write(w, p)
+		err.add(i, w, e)
 	}
 	if len(err) == 0 {
 		return len(p), nil
@@ -84,12 +129,9 @@ func (t *multiWriteCloser) Write(p []byte) (int, error) {
 
 // Close calls Close on all it's io.CloseWriters in closeWriters.
 func (t *multiWriteCloser) Close() error {
-	var err multiError
-	for _, wc := range t.writers {
-		e := wc.Close()
-		if e != nil {
-			err = append(err, e)
-		}
+	var err MultiError
+	for i, wc := range t.writers {
+		err.add(i, wc, wc.Close())
 	}
 	if len(err) == 0 {
 		return nil
@@ -182,3 +182,49 @@ func TestCloseError(t *testing.T) {
 		}
 	}
 }
+
+// shortWriteCloser writes at most half of each write without error.
+type shortWriteCloser struct{ testWriteCloser }
+
+func (wc *shortWriteCloser) Write(d []byte) (int, error) {
+	return wc.testWriteCloser.Write(d[:len(d)/2])
+}
+
+// TestMultiError checks that errors identify the failing writers, match
+// with errors.Is and errors.As, and include short writes.
+func TestMultiError(t *testing.T) {
+	short := &shortWriteCloser{}
+	failing := &testWriteCloser{failOnWrite: true}
+	mwc := MultiWriteCloser(&testWriteCloser{}, short, failing)
+
+	_, err := mwc.Write([]byte{0x01, 0x02, 0x03, 0x04})
+	if !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("expected error to match io.ErrShortWrite, got: %v", err)
+	}
+
+	var me MultiError
+	if !errors.As(err, &me) || len(me) != 2 {
+		t.Fatalf("expected MultiError with 2 errors, got: %v", err)
+	}
+	if me[0].Index != 1 || me[0].Writer != short {
+		t.Errorf("unexpected first error: %+v", me[0])
+	}
+	if me[1].Index != 2 || me[1].Writer != failing {
+		t.Errorf("unexpected second error: %+v", me[1])
+	}
+
+	var we *WriterError
+	if !errors.As(err, &we) || we != me[0] {
+		t.Errorf("expected errors.As to find first WriterError, got: %v", we)
+	}
+
+	want := "writer 1: short write; writer 2: failed to write"
+	if err.Error() != want {
+		t.Errorf("unexpected error string.\nGot: %v\nWant: %v", err, want)
+	}
+
+	joined := errors.Join(errors.New("other"), err)
+	if !errors.Is(joined, io.ErrShortWrite) {
+		t.Errorf("expected joined error to match io.ErrShortWrite")
+	}
+}
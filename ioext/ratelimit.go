@@ -0,0 +1,315 @@
+/*
+NAME
+  ratelimit.go
+
+DESCRIPTION
+  ratelimit.go provides a token bucket Limiter and rate-limited io.Reader and
+  io.Writer wrappers for capping the bytes per second transferred.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ausocean/utils/bitrate"
+)
+
+// clock provides the time, and is replaced in tests.
+type clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the system clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Limiter is a token bucket limiting a rate in bytes per second. The bucket
+// holds up to burst bytes, so transfers of up to burst bytes may happen at
+// once after a quiet period. The rate and burst may be changed while in
+// use. A Limiter is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   int     // Bytes per second; zero or less is unlimited.
+	burst  int     // Capacity of the bucket in bytes.
+	set    int     // Burst as set; zero or less follows the rate.
+	tokens float64 // Bytes available, negative when waits are reserved.
+	last   time.Time
+	clock  clock
+}
+
+// NewLimiter returns a Limiter allowing rate bytes per second with bursts of
+// up to burst bytes. The bucket starts full. A burst of zero or less is one
+// second's worth at the given rate.
+func NewLimiter(rate, burst int) *Limiter {
+	return newLimiter(rate, burst, realClock{})
+}
+
+func newLimiter(rate, burst int, c clock) *Limiter {
+	l := &Limiter{rate: rate, set: burst, clock: c, last: c.Now()}
+	l.updateBurst()
+	l.tokens = float64(l.burst)
+	return l
+}
+
+// updateBurst sets the burst to that set, or to one second's worth if not
+// set, and clamps the tokens to it. It must be called with the lock held.
+func (l *Limiter) updateBurst() {
+	switch {
+	case l.set > 0:
+		l.burst = l.set
+	case l.rate > 0:
+		l.burst = l.rate
+	default:
+		l.burst = 1
+	}
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+}
+
+// advance adds the tokens accumulated since last called. It must be called
+// with the lock held.
+func (l *Limiter) advance() {
+	now := l.clock.Now()
+	if l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * float64(l.rate)
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+}
+
+// SetRate sets the rate in bytes per second. A rate of zero or less is
+// unlimited. If the burst was not set, it becomes one second's worth at the
+// new rate.
+func (l *Limiter) SetRate(rate int) {
+	l.mu.Lock()
+	l.advance()
+	unlimited := l.rate <= 0
+	l.rate = rate
+	l.updateBurst()
+	if unlimited {
+		// The bucket of an unlimited Limiter is full.
+		l.tokens = float64(l.burst)
+	}
+	l.mu.Unlock()
+}
+
+// SetBurst sets the burst in bytes. A burst of zero or less is one second's
+// worth at the current rate, following later changes of the rate.
+func (l *Limiter) SetBurst(burst int) {
+	l.mu.Lock()
+	l.advance()
+	l.set = burst
+	l.updateBurst()
+	l.mu.Unlock()
+}
+
+// Rate returns the rate in bytes per second.
+func (l *Limiter) Rate() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Burst returns the burst in bytes.
+func (l *Limiter) Burst() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.burst
+}
+
+// Wait waits until up to n bytes may be transferred, or ctx is done, and
+// returns the number of bytes granted, which is n limited to the burst.
+// Waiters are served in the order they call Wait. No bytes are granted if
+// ctx is done first.
+func (l *Limiter) Wait(ctx context.Context, n int) (int, error) {
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return n, nil
+	}
+	if n > l.burst {
+		n = l.burst
+	}
+	l.advance()
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return n, nil
+	}
+	wait := time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+	l.mu.Unlock()
+
+	err := l.clock.Sleep(ctx, wait)
+	if err != nil {
+		// Return the reservation.
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return 0, err
+	}
+	return n, nil
+}
+
+// chunk returns the largest transfer up to n bytes likely to be granted by
+// a single Wait.
+func (l *Limiter) chunk(n int) int {
+	burst := l.Burst()
+	if n > burst {
+		return burst
+	}
+	return n
+}
+
+// waitAll waits until all n bytes have been granted, or ctx is done.
+func (l *Limiter) waitAll(ctx context.Context, n int) error {
+	for n > 0 {
+		granted, err := l.Wait(ctx, n)
+		if err != nil {
+			return err
+		}
+		n -= granted
+	}
+	return nil
+}
+
+// RateLimitedWriter is an io.Writer that limits the rate of writes to an
+// underlying io.Writer using a Limiter. Writes larger than the burst are
+// split.
+type RateLimitedWriter struct {
+	ctx  context.Context
+	w    io.Writer
+	l    *Limiter
+	calc *bitrate.Calculator
+}
+
+// NewRateLimitedWriter returns a RateLimitedWriter writing to w at the rate
+// allowed by l. Writes return ctx.Err() once ctx is done. The Limiter may
+// be shared with other readers and writers to limit their combined rate.
+func NewRateLimitedWriter(ctx context.Context, w io.Writer, l *Limiter) *RateLimitedWriter {
+	return &RateLimitedWriter{ctx: ctx, w: w, l: l}
+}
+
+// SetCalculator sets a bitrate.Calculator to which written bytes are
+// reported.
+func (w *RateLimitedWriter) SetCalculator(c *bitrate.Calculator) { w.calc = c }
+
+// Write implements io.Writer.
+func (w *RateLimitedWriter) Write(p []byte) (int, error) {
+	var total int
+	for len(p) != 0 {
+		n, err := w.l.Wait(w.ctx, len(p))
+		if err != nil {
+			return total, err
+		}
+		n, err = w.w.Write(p[:n])
+		total += n
+		if w.calc != nil {
+			w.calc.Report(n)
+		}
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+	}
+	return total, nil
+}
+
+// Bitrates returns the measured bitrate since last called, if a Calculator
+// is set, and the limit, both in bits per second.
+func (w *RateLimitedWriter) Bitrates() (measured, limit int) {
+	return bitrates(w.calc, w.l)
+}
+
+// RateLimitedReader is an io.Reader that limits the rate of reads from an
+// underlying io.Reader using a Limiter. Reads are limited to the burst.
+type RateLimitedReader struct {
+	ctx  context.Context
+	r    io.Reader
+	l    *Limiter
+	calc *bitrate.Calculator
+}
+
+// NewRateLimitedReader returns a RateLimitedReader reading from r at the
+// rate allowed by l. Reads return ctx.Err() once ctx is done.
+func NewRateLimitedReader(ctx context.Context, r io.Reader, l *Limiter) *RateLimitedReader {
+	return &RateLimitedReader{ctx: ctx, r: r, l: l}
+}
+
+// SetCalculator sets a bitrate.Calculator to which read bytes are reported.
+func (r *RateLimitedReader) SetCalculator(c *bitrate.Calculator) { r.calc = c }
+
+// Read implements io.Reader. The bytes read are paid for after reading, so
+// that reads returning less than requested are not overcharged.
+func (r *RateLimitedReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p[:r.l.chunk(len(p))])
+	if r.calc != nil {
+		r.calc.Report(n)
+	}
+	if n > 0 {
+		werr := r.l.waitAll(r.ctx, n)
+		if err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
+
+// Bitrates returns the measured bitrate since last called, if a Calculator
+// is set, and the limit, both in bits per second.
+func (r *RateLimitedReader) Bitrates() (measured, limit int) {
+	return bitrates(r.calc, r.l)
+}
+
+// bitrates returns the bitrate measured by c, or zero if c is nil, and the
+// limit of l in bits per second.
+func bitrates(c *bitrate.Calculator, l *Limiter) (measured, limit int) {
+	if c != nil {
+		measured = c.Bitrate()
+	}
+	return measured, l.Rate() * 8
+}
@@ -0,0 +1,252 @@
+/*
+NAME
+  ratelimit_test.go
+
+DESCRIPTION
+  ratelimit_test.go provides testing functionality for the Limiter and
+  rate-limited wrappers found in ratelimit.go.
+
+AUTHORS
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean)
+
+  This is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see http://www.gnu.org/licenses.
+*/
+
+package ioext
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ausocean/utils/bitrate"
+)
+
+// fakeClock is a clock whose time advances only when slept on or advanced.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+	added  chan struct{} // Signalled when a timer is added.
+}
+
+// fakeTimer is a pending channel returned by fakeClock.After.
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0), added: make(chan struct{}, 1)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.Advance(d)
+	return nil
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), c: ch})
+	select {
+	case c.added <- struct{}{}:
+	default:
+	}
+	return ch
+}
+
+// Advance advances the time by d, firing any timers that fall due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			timers = append(timers, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.timers = timers
+}
+
+// waitForTimer waits until a timer has been added since the last call.
+func (c *fakeClock) waitForTimer() {
+	select {
+	case <-c.added:
+	case <-time.After(time.Second):
+	}
+}
+
+// elapsed returns the time since the fake clock's start.
+func (c *fakeClock) elapsed() time.Duration { return c.Now().Sub(time.Unix(0, 0)) }
+
+// within returns true if got is within a millisecond of want.
+func within(got, want time.Duration) bool {
+	d := got - want
+	return d > -time.Millisecond && d < time.Millisecond
+}
+
+// TestRateLimitedWriter checks that writes are limited to the rate after
+// the initial burst, and that the rate can be changed.
+func TestRateLimitedWriter(t *testing.T) {
+	c := newFakeClock()
+	l := newLimiter(100, 10, c)
+	var buf bytes.Buffer
+	w := NewRateLimitedWriter(context.Background(), &buf, l)
+
+	n, err := w.Write(make([]byte, 100))
+	if n != 100 || err != nil {
+		t.Fatalf("Write returned %d, %v", n, err)
+	}
+	if buf.Len() != 100 {
+		t.Errorf("wrote %d bytes, want 100", buf.Len())
+	}
+	// The first 10 bytes are free, the remaining 90 take 0.9s.
+	if !within(c.elapsed(), 900*time.Millisecond) {
+		t.Errorf("took %v, want 900ms", c.elapsed())
+	}
+
+	l.SetRate(1000)
+	start := c.elapsed()
+	w.Write(make([]byte, 100))
+	if d := c.elapsed() - start; !within(d, 100*time.Millisecond) {
+		t.Errorf("took %v after SetRate, want 100ms", d)
+	}
+
+	// A quiet period refills the bucket, but only up to the burst.
+	c.Advance(time.Hour)
+	start = c.elapsed()
+	w.Write(make([]byte, 20))
+	if d := c.elapsed() - start; !within(d, 10*time.Millisecond) {
+		t.Errorf("took %v after quiet period, want 10ms", d)
+	}
+
+	l.SetRate(0)
+	start = c.elapsed()
+	w.Write(make([]byte, 1000))
+	if d := c.elapsed() - start; d != 0 {
+		t.Errorf("took %v when unlimited, want 0", d)
+	}
+}
+
+// TestRateLimitedReader checks that reads are limited to the rate.
+func TestRateLimitedReader(t *testing.T) {
+	c := newFakeClock()
+	l := newLimiter(50, 0, c) // The burst defaults to one second's worth.
+	if l.Burst() != 50 {
+		t.Errorf("got burst %d, want 50", l.Burst())
+	}
+	r := NewRateLimitedReader(context.Background(), bytes.NewReader(make([]byte, 200)), l)
+
+	got, err := io.ReadAll(r)
+	if err != nil || len(got) != 200 {
+		t.Fatalf("ReadAll returned %d bytes, %v", len(got), err)
+	}
+	if !within(c.elapsed(), 3*time.Second) {
+		t.Errorf("took %v, want 3s", c.elapsed())
+	}
+}
+
+// TestRateLimitCancel checks that waits end when the context is done.
+func TestRateLimitCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var buf bytes.Buffer
+	w := NewRateLimitedWriter(ctx, &buf, NewLimiter(1, 1))
+
+	start := time.Now()
+	n, err := w.Write(make([]byte, 10))
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n != 1 {
+		t.Errorf("wrote %d bytes, want 1", n)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Write took %v after cancellation", d)
+	}
+
+}
+
+// TestLimiterBurst checks that an unset burst follows the rate, and that
+// waits are limited to the burst even as it changes.
+func TestLimiterBurst(t *testing.T) {
+	c := newFakeClock()
+	l := newLimiter(0, 0, c)
+	l.SetRate(1 << 20)
+	if l.Burst() != 1<<20 {
+		t.Errorf("got burst %d after SetRate, want %d", l.Burst(), 1<<20)
+	}
+	var buf bytes.Buffer
+	w := NewRateLimitedWriter(context.Background(), &buf, l)
+	n, err := w.Write(make([]byte, 1<<20))
+	if n != 1<<20 || err != nil {
+		t.Fatalf("Write returned %d, %v", n, err)
+	}
+	if c.elapsed() != 0 {
+		t.Errorf("took %v within the burst, want 0", c.elapsed())
+	}
+
+	l.SetBurst(10)
+	l.SetRate(100)
+	if l.Burst() != 10 {
+		t.Errorf("got burst %d after SetRate, want the set burst of 10", l.Burst())
+	}
+	n, err = l.Wait(context.Background(), 25)
+	if n != 10 || err != nil {
+		t.Errorf("Wait returned %d, %v, want 10 bytes granted", n, err)
+	}
+
+	l.SetBurst(0)
+	if l.Burst() != 100 {
+		t.Errorf("got burst %d after unsetting, want 100", l.Burst())
+	}
+}
+
+// TestRateLimitBitrates checks reporting of the measured rate and limit.
+func TestRateLimitBitrates(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewRateLimitedWriter(context.Background(), &buf, NewLimiter(1000, 0))
+	calc := bitrate.NewCalculator()
+	w.SetCalculator(calc)
+
+	w.Write(make([]byte, 100))
+	time.Sleep(10 * time.Millisecond)
+	measured, limit := w.Bitrates()
+	if limit != 8000 {
+		t.Errorf("got limit %d, want 8000", limit)
+	}
+	if measured <= 0 || measured > 100*8*1000/10 {
+		t.Errorf("got measured bitrate %d, want between 0 and 80000", measured)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+DESCRIPTION
+  capture.go provides an implementation of the Logger interface that records
+  log entries so that tests may assert that particular entries were logged.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// CaptureLogger provides an implementation of Logger that records each entry
+// for later inspection by a test, as well as writing it to the test log.
+// Entries of all levels are recorded; SetLevel has no effect. Fatal entries
+// are recorded and, unlike other loggers, return to the caller.
+type CaptureLogger struct {
+	t           testing.TB
+	entries     []capturedEntry
+	failOnError bool
+	mu          sync.Mutex
+}
+
+// capturedEntry is an entry recorded by a CaptureLogger.
+type capturedEntry struct {
+	Entry
+	asserted bool // Indicates the entry was matched by AssertLogged.
+}
+
+// NewCaptureLogger returns a new CaptureLogger for use in the test t.
+func NewCaptureLogger(t testing.TB) *CaptureLogger {
+	c := &CaptureLogger{t: t}
+	t.Cleanup(c.checkErrors)
+	return c
+}
+
+// Logging methods for each level.
+func (c *CaptureLogger) Debug(msg string, args ...interface{})           { c.log(Debug, msg, args...) }
+func (c *CaptureLogger) Info(msg string, args ...interface{})            { c.log(Info, msg, args...) }
+func (c *CaptureLogger) Warning(msg string, args ...interface{})         { c.log(Warning, msg, args...) }
+func (c *CaptureLogger) Error(msg string, args ...interface{})           { c.log(Error, msg, args...) }
+func (c *CaptureLogger) Fatal(msg string, args ...interface{})           { c.log(Fatal, msg, args...) }
+func (c *CaptureLogger) Log(level int8, msg string, args ...interface{}) { c.log(level, msg, args...) }
+func (c *CaptureLogger) SetLevel(level int8)                             {}
+
+// log records the entry and writes it to the test log. Malformed parameters
+// are reported with an additional Error entry, as for a JSONLogger in
+// development mode.
+func (c *CaptureLogger) log(level int8, message string, args ...interface{}) {
+	c.t.Helper()
+	var caller string
+	// Skip log and the exported logging method.
+	if pc, file, line, ok := runtime.Caller(2); ok {
+		caller = zapcore.NewEntryCaller(pc, file, line, true).TrimmedPath()
+	}
+	c.add(Entry{Time: time.Now(), Level: level, Message: message, Caller: caller, Fields: fieldsOf(args)})
+
+	_, err := normalise(args)
+	if err != nil {
+		c.add(Entry{
+			Time:    time.Now(),
+			Level:   Error,
+			Message: malformedMessage,
+			Caller:  caller,
+			Fields:  map[string]interface{}{"error": err.Error(), malformedMessageKey: message},
+		})
+	}
+}
+
+// add records e and writes it to the test log.
+func (c *CaptureLogger) add(e Entry) {
+	c.mu.Lock()
+	c.entries = append(c.entries, capturedEntry{Entry: e})
+	c.mu.Unlock()
+
+	c.t.Helper()
+	if len(e.Fields) == 0 {
+		c.t.Logf("%s: %s: %s", e.Caller, levelName(e.Level), e.Message)
+		return
+	}
+	c.t.Logf("%s: %s: %s %v", e.Caller, levelName(e.Level), e.Message, e.Fields)
+}
+
+// FailOnError causes the test to fail when it finishes if any Error or Fatal
+// entries were logged that were not matched by a call to AssertLogged.
+func (c *CaptureLogger) FailOnError(fail bool) {
+	c.mu.Lock()
+	c.failOnError = fail
+	c.mu.Unlock()
+}
+
+// Entries returns the recorded entries in the order they were logged.
+func (c *CaptureLogger) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]Entry, len(c.entries))
+	for i, e := range c.entries {
+		entries[i] = e.Entry
+	}
+	return entries
+}
+
+// Reset discards the recorded entries.
+func (c *CaptureLogger) Reset() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+// AssertLogged fails the test t unless an entry was logged at level with a
+// message containing msg and with each of the key:value pairs in kv. Values
+// are compared using their %v formatting.
+func (c *CaptureLogger) AssertLogged(t testing.TB, level int8, msg string, kv ...interface{}) {
+	t.Helper()
+	if !c.match(level, msg, kv) {
+		t.Errorf("no %s entry logged containing %q with %v", levelName(level), msg, fieldsOf(kv))
+	}
+}
+
+// Logged returns true if an entry was logged at level with a message
+// containing msg and with each of the key:value pairs in kv.
+func (c *CaptureLogger) Logged(level int8, msg string, kv ...interface{}) bool {
+	return c.match(level, msg, kv)
+}
+
+// match reports whether there are entries matching the arguments and marks
+// them as asserted.
+func (c *CaptureLogger) match(level int8, msg string, kv []interface{}) bool {
+	want := fieldsOf(kv)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var found bool
+next:
+	for i, e := range c.entries {
+		if e.Level != level || !strings.Contains(e.Message, msg) {
+			continue
+		}
+		for k, v := range want {
+			got, ok := e.Fields[k]
+			if !ok || fmt.Sprint(got) != fmt.Sprint(v) {
+				continue next
+			}
+		}
+		c.entries[i].asserted = true
+		found = true
+	}
+	return found
+}
+
+// checkErrors fails the test if FailOnError is set and there are unasserted
+// Error or Fatal entries.
+func (c *CaptureLogger) checkErrors() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.failOnError {
+		return
+	}
+	for _, e := range c.entries {
+		if e.Level >= Error && !e.asserted {
+			c.t.Errorf("unexpected %s entry logged at %s: %s %v", levelName(e.Level), e.Caller, e.Message, e.Fields)
+		}
+	}
+}
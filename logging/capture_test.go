@@ -0,0 +1,109 @@
+/*
+DESCRIPTION
+  capture_test.go provides testing for functionality found in capture.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeTB records failures and cleanups rather than acting on them, so that
+// we can test the failure behaviour of the CaptureLogger.
+type fakeTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper()                                 {}
+func (f *fakeTB) Logf(format string, args ...interface{}) {}
+func (f *fakeTB) Cleanup(fn func())                       { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) finish() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
+
+// TestCaptureLogger tests recording and asserting entries.
+func TestCaptureLogger(t *testing.T) {
+	c := NewCaptureLogger(t)
+	var logger Logger = c
+
+	logger.Info("starting", "id", 7)
+	logger.Warning("could not connect to server", "attempt", 2, "host", "localhost")
+
+	entries := c.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("unexpected number of entries: got: %d want: 2", len(entries))
+	}
+	if !strings.HasPrefix(entries[0].Caller, "logging/capture_test.go:") {
+		t.Errorf("unexpected caller: %s", entries[0].Caller)
+	}
+
+	c.AssertLogged(t, Warning, "could not connect", "attempt", 2)
+	c.AssertLogged(t, Info, "start", "id", "7")
+	if c.Logged(Warning, "could not connect", "attempt", 3) {
+		t.Error("did not expect match with wrong value")
+	}
+	if c.Logged(Error, "could not connect") {
+		t.Error("did not expect match with wrong level")
+	}
+
+	c.Reset()
+	if len(c.Entries()) != 0 {
+		t.Error("expected no entries after reset")
+	}
+}
+
+// TestCaptureLoggerFailures tests that failed assertions and unexpected errors
+// fail the test.
+func TestCaptureLoggerFailures(t *testing.T) {
+	tb := &fakeTB{}
+	c := NewCaptureLogger(tb)
+	c.FailOnError(true)
+
+	c.Error("write failed")
+	c.Error("read failed")
+	c.Warning("warning")
+
+	c.AssertLogged(tb, Error, "write failed")
+	c.AssertLogged(tb, Info, "missing")
+	tb.finish()
+
+	if len(tb.errors) != 2 {
+		t.Fatalf("unexpected number of test errors: got: %d want: 2\n%v", len(tb.errors), tb.errors)
+	}
+	if !strings.Contains(tb.errors[0], "missing") {
+		t.Errorf("unexpected assertion error: %s", tb.errors[0])
+	}
+	if !strings.Contains(tb.errors[1], "read failed") {
+		t.Errorf("unexpected cleanup error: %s", tb.errors[1])
+	}
+}
@@ -0,0 +1,76 @@
+/*
+DESCRIPTION
+  console.go provides a human-readable output format for the JSONLogger,
+  intended for reading logs at a terminal, e.g. while debugging on the bench.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"io"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Format is the output format of a logger.
+type Format int
+
+// Output formats.
+const (
+	JSONFormat    Format = iota // One JSON object per line.
+	ConsoleFormat               // Tab separated, with coloured and aligned levels.
+)
+
+// ANSI colour escape codes used for each level.
+var levelColours = map[zapcore.Level]string{
+	zapcore.DebugLevel: "\x1b[35m", // Magenta.
+	zapcore.InfoLevel:  "\x1b[34m", // Blue.
+	zapcore.WarnLevel:  "\x1b[33m", // Yellow.
+	zapcore.ErrorLevel: "\x1b[31m", // Red.
+	zapcore.FatalLevel: "\x1b[31m", // Red.
+}
+
+const colourReset = "\x1b[0m"
+
+// NewConsole returns a new JSONLogger that writes human-readable, rather than
+// JSON, output. Levels, caller reporting and suppression behave exactly as for
+// a logger returned by New, and the format may be changed later using
+// SetFormat. Optionally, a zapcore encoder config can be passed, otherwise a
+// default is used.
+func NewConsole(verbosity int8, writer io.Writer, suppress bool, config ...zapcore.EncoderConfig) *JSONLogger {
+	return newLogger(ConsoleFormat, verbosity, writer, suppress, config...)
+}
+
+// colourLevelEncoder encodes levels as coloured, upper case strings padded
+// to a common width so that the following columns line up.
+func colourLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	s := l.CapitalString()
+	for len(s) < len("DEBUG") {
+		s += " "
+	}
+	c, ok := levelColours[l]
+	if !ok {
+		enc.AppendString(s)
+		return
+	}
+	enc.AppendString(c + s + colourReset)
+}
@@ -0,0 +1,120 @@
+/*
+DESCRIPTION
+  entry.go provides a structured representation of a log entry and a means of
+  adapting functions that handle entries to zapcore cores, for use by sinks
+  that do not simply encode entries to an io.Writer.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Entry is a structured log entry.
+type Entry struct {
+	Time    time.Time
+	Level   int8
+	Message string
+	Caller  string                 // The file and line of the caller, if known.
+	Stack   string                 // The stack trace, if captured.
+	Fields  map[string]interface{} // The key:value pairs logged with the message.
+}
+
+// MarshalJSON implements json.Marshaler, encoding the level by name.
+func (e Entry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Time    time.Time              `json:"time"`
+		Level   string                 `json:"level"`
+		Message string                 `json:"message"`
+		Caller  string                 `json:"caller,omitempty"`
+		Stack   string                 `json:"stackTrace,omitempty"`
+		Fields  map[string]interface{} `json:"fields,omitempty"`
+	}{
+		Time:    e.Time,
+		Level:   zapcore.Level(e.Level).String(),
+		Message: e.Message,
+		Caller:  e.Caller,
+		Stack:   e.Stack,
+		Fields:  e.Fields,
+	})
+}
+
+// newEntry returns the Entry for a zapcore entry and its fields.
+func newEntry(ent zapcore.Entry, fields []zapcore.Field) Entry {
+	e := Entry{
+		Time:    ent.Time,
+		Level:   int8(ent.Level),
+		Message: ent.Message,
+		Stack:   ent.Stack,
+	}
+	if ent.Caller.Defined {
+		e.Caller = ent.Caller.TrimmedPath()
+	}
+	if len(fields) != 0 {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, f := range fields {
+			f.AddTo(enc)
+		}
+		e.Fields = enc.Fields
+	}
+	return e
+}
+
+// entryCore implements zapcore.Core by passing each enabled entry, with the
+// fields accumulated by With, to a function.
+type entryCore struct {
+	zapcore.LevelEnabler
+	fields []zapcore.Field
+	write  func(Entry) error
+	sync   func() error
+}
+
+func (c *entryCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	return &clone
+}
+
+func (c *entryCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *entryCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if len(c.fields) != 0 {
+		fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	}
+	return c.write(newEntry(ent, fields))
+}
+
+func (c *entryCore) Sync() error {
+	if c.sync == nil {
+		return nil
+	}
+	return c.sync()
+}
@@ -0,0 +1,97 @@
+/*
+DESCRIPTION
+  fatal.go provides configurable behaviour for loggers after a Fatal entry
+  has been written, allowing applications to clean up before exiting and
+  tests to observe Fatal calls.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"os"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FatalAction is the action taken by a logger after writing a Fatal entry.
+type FatalAction int
+
+// Fatal actions.
+const (
+	FatalExit   FatalAction = iota // Exit the program with status 1.
+	FatalPanic                     // Panic with the entry's message.
+	FatalReturn                    // Return to the caller, e.g. to observe Fatal calls in tests.
+)
+
+// FatalHook describes the behaviour of a logger after writing a Fatal entry.
+// The zero value exits immediately. For example, to flush buffers before
+// exiting, waiting no more than five seconds:
+//
+//	l.SetFatalHook(logging.FatalHook{Cleanup: flush, Timeout: 5 * time.Second})
+type FatalHook struct {
+	Action  FatalAction   // The action taken after Cleanup.
+	Cleanup func()        // Called before the action is taken, if non-nil.
+	Timeout time.Duration // The maximum time to wait for Cleanup. Zero waits indefinitely.
+}
+
+// exit is used by FatalExit. It is a variable so that it may be replaced in
+// testing.
+var exit = os.Exit
+
+// run carries out the hook for a Fatal entry with the given message. The
+// cleanup function may itself log using the logger that is exiting.
+func (h FatalHook) run(message string) {
+	if h.Cleanup != nil {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			h.Cleanup()
+		}()
+		if h.Timeout > 0 {
+			timer := time.NewTimer(h.Timeout)
+			select {
+			case <-done:
+				timer.Stop()
+			case <-timer.C:
+			}
+		} else {
+			<-done
+		}
+	}
+
+	switch h.Action {
+	case FatalPanic:
+		panic(message)
+	case FatalReturn:
+		return
+	default:
+		exit(1)
+	}
+}
+
+// deferFatal is a zapcore.CheckWriteHook that does nothing. It is given to
+// zap in place of its default fatal hook so that the loggers of this package
+// can carry out their FatalHook once they have released any locks.
+type deferFatal struct{}
+
+func (deferFatal) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
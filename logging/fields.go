@@ -0,0 +1,148 @@
+/*
+DESCRIPTION
+  fields.go provides strongly typed key:value fields for use with the Logger
+  logging methods, and the normalisation of key:value parameter lists that is
+  shared by the Logger implementations of this package.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field is a strongly typed key:value pair. Fields may be passed to the
+// logging methods in place of a key and its value, e.g.
+//
+//	log.Error("could not read sensor", logging.String("sensor", name), logging.Err(err))
+type Field = zapcore.Field
+
+// Typed field constructors.
+func String(key, val string) Field                 { return zap.String(key, val) }
+func Int(key string, val int) Field                { return zap.Int(key, val) }
+func Int64(key string, val int64) Field            { return zap.Int64(key, val) }
+func Uint64(key string, val uint64) Field          { return zap.Uint64(key, val) }
+func Float64(key string, val float64) Field        { return zap.Float64(key, val) }
+func Bool(key string, val bool) Field              { return zap.Bool(key, val) }
+func Duration(key string, val time.Duration) Field { return zap.Duration(key, val) }
+func Time(key string, val time.Time) Field         { return zap.Time(key, val) }
+func Any(key string, val interface{}) Field        { return zap.Any(key, val) }
+
+// Err returns a field holding err with the key "error". A nil err is omitted.
+func Err(err error) Field { return zap.Error(err) }
+
+// The message and key of the entry reporting malformed parameters.
+const (
+	malformedMessage    = "malformed log parameters"
+	malformedMessageKey = "logMessage"
+)
+
+// badKey is the key given to values that are not preceded by a string key,
+// as for log/slog.
+const badKey = "!BADKEY"
+
+// Errors describing malformed parameter lists.
+var (
+	errMissingValue = errors.New("key without a value")
+	errNonStringKey = errors.New("value without a string key")
+)
+
+// normalise returns the key:value pairs and Fields in params as Fields.
+// Malformed lists are handled in the same way as log/slog, i.e. a non-string
+// where a key is expected, or a key without a value, is logged as a value
+// with the key "!BADKEY". In that case the returned error describes the
+// first problem found.
+func normalise(params []interface{}) ([]Field, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	fields := make([]Field, 0, len(params)/2+1)
+	var err error
+	for i := 0; i < len(params); {
+		switch p := params[i].(type) {
+		case Field:
+			fields = append(fields, p)
+			i++
+		case string:
+			if i+1 == len(params) {
+				fields = append(fields, zap.String(badKey, p))
+				if err == nil {
+					err = fmt.Errorf("%w: %q", errMissingValue, p)
+				}
+				i++
+				continue
+			}
+			fields = append(fields, zap.Any(p, params[i+1]))
+			i += 2
+		default:
+			fields = append(fields, zap.Any(badKey, p))
+			if err == nil {
+				err = fmt.Errorf("%w: %v at position %d", errNonStringKey, p, i)
+			}
+			i++
+		}
+	}
+	return fields, err
+}
+
+// fieldValue returns the value held by f as it would be encoded, and false if
+// f is a field that is not encoded, e.g. Err(nil).
+func fieldValue(f Field) (interface{}, bool) {
+	if f.Type == zapcore.SkipType {
+		return nil, false
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	v, ok := enc.Fields[f.Key]
+	return v, ok
+}
+
+// fieldsOf returns the key:value pairs in params as a map, normalised as
+// for logging.
+func fieldsOf(params []interface{}) map[string]interface{} {
+	fields, _ := normalise(params)
+	if len(fields) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if v, ok := fieldValue(f); ok {
+			m[f.Key] = v
+		}
+	}
+	return m
+}
+
+// interfaces returns fields as a slice of interface{} for passing to the
+// zap.SugaredLogger logging methods.
+func interfaces(fields []Field) []interface{} {
+	s := make([]interface{}, len(fields))
+	for i, f := range fields {
+		s[i] = f
+	}
+	return s
+}
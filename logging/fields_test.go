@@ -0,0 +1,102 @@
+/*
+DESCRIPTION
+  fields_test.go provides testing for functionality found in fields.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNormalise tests the normalisation of key:value parameter lists.
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		params  []interface{}
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			params: []interface{}{"a", 1, String("b", "x"), "c", true},
+			want:   map[string]interface{}{"a": int64(1), "b": "x", "c": true},
+		},
+		{
+			params:  []interface{}{"a", 1, "b"},
+			want:    map[string]interface{}{"a": int64(1), badKey: "b"},
+			wantErr: errMissingValue,
+		},
+		{
+			params:  []interface{}{errors.New("oops"), "a", 1},
+			want:    map[string]interface{}{badKey: "oops", "a": int64(1)},
+			wantErr: errNonStringKey,
+		},
+		{
+			params: []interface{}{Err(nil), Duration("d", time.Second)},
+			want:   map[string]interface{}{"d": time.Second},
+		},
+	}
+
+	for i, test := range tests {
+		_, err := normalise(test.params)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("test %d: unexpected error: got: %v want: %v", i, err, test.wantErr)
+		}
+		got := fieldsOf(test.params)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("test %d: unexpected fields: got: %#v want: %#v", i, got, test.want)
+		}
+	}
+}
+
+// TestDevMode tests that malformed parameters are logged consistently and,
+// in development mode, reported with the caller of the offending call.
+func TestDevMode(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Debug, &buf, false)
+
+	l.Info("typed", Int("n", 3), Err(errors.New("failed")), "key")
+	got := buf.String()
+	for _, want := range []string{`"n":3`, `"error":"failed"`, `"!BADKEY":"key"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %s, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, malformedMessage) {
+		t.Errorf("did not expect report outside development mode, got:\n%s", got)
+	}
+
+	buf.Reset()
+	l.SetDevMode(true)
+	l.Info("odd", "key")
+	// The report should be the first entry.
+	got = strings.Split(buf.String(), "\n")[0]
+	for _, want := range []string{`"caller":"logging/fields_test.go`, malformedMessage, `"logMessage":"odd"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %s, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+/*
+DESCRIPTION
+  handler.go provides an http.Handler allowing the level, suppression and
+  caller filter settings of a JSONLogger to be read and changed at runtime.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Settings holds the runtime adjustable settings of a JSONLogger, as
+// exchanged with the handler returned by NewHandler.
+type Settings struct {
+	Level         string   `json:"level"`         // One of debug, info, warning, error or fatal.
+	Suppress      bool     `json:"suppress"`      // Whether repeated messages are suppressed.
+	SamplerTick   string   `json:"samplerTick"`   // The suppression interval, e.g. "1m0s".
+	LogFirst      int      `json:"logFirst"`      // Messages logged per tick before suppressing.
+	ThenEvery     int      `json:"thenEvery"`     // Then log every thenEvery messages per tick.
+	CallerFilters []string `json:"callerFilters"` // Caller files that are not logged.
+}
+
+// settingsUpdate holds a partial update of Settings. Nil fields are left
+// unchanged.
+type settingsUpdate struct {
+	Level         *string   `json:"level"`
+	Suppress      *bool     `json:"suppress"`
+	SamplerTick   *string   `json:"samplerTick"`
+	LogFirst      *int      `json:"logFirst"`
+	ThenEvery     *int      `json:"thenEvery"`
+	CallerFilters *[]string `json:"callerFilters"`
+}
+
+// handler serves the settings of a JSONLogger.
+type handler struct {
+	l *JSONLogger
+}
+
+// NewHandler returns an http.Handler for reading and changing the settings of
+// l. A GET request responds with the current Settings as JSON. A PUT request
+// takes a JSON object holding any subset of the Settings fields, applies them
+// and responds with the resulting Settings. For example:
+//
+//	PUT {"level": "debug", "callerFilters": ["netsender.go"]}
+func NewHandler(l *JSONLogger) http.Handler {
+	return &handler{l: l}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var u settingsUpdate
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&u)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("could not decode settings: %v", err), http.StatusBadRequest)
+			return
+		}
+		err = h.l.applySettings(u)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.l.settings())
+}
+
+// settings returns the current settings of the logger.
+func (l *JSONLogger) settings() Settings {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	filters := append([]string{}, l.callerFilters...)
+	return Settings{
+		Level:         levelName(l.verbosity),
+		Suppress:      l.suppress,
+		SamplerTick:   l.samplerTick.String(),
+		LogFirst:      l.logFirst,
+		ThenEvery:     l.thenEvery,
+		CallerFilters: filters,
+	}
+}
+
+// applySettings validates u and, if it is valid, applies it to the logger.
+// Either all or none of the settings in u are applied.
+func (l *JSONLogger) applySettings(u settingsUpdate) error {
+	var (
+		level int8
+		tick  time.Duration
+		err   error
+	)
+	if u.Level != nil {
+		level, err = parseLevel(*u.Level)
+		if err != nil {
+			return err
+		}
+	}
+	if u.SamplerTick != nil {
+		tick, err = time.ParseDuration(*u.SamplerTick)
+		if err != nil {
+			return fmt.Errorf("invalid sampler tick: %w", err)
+		}
+		if tick <= 0 {
+			return errors.New("sampler tick must be positive")
+		}
+	}
+	if u.LogFirst != nil && *u.LogFirst < 0 {
+		return errors.New("logFirst must not be negative")
+	}
+	if u.ThenEvery != nil && *u.ThenEvery < 0 {
+		return errors.New("thenEvery must not be negative")
+	}
+
+	l.update(func() {
+		if u.Level != nil {
+			l.verbosity = level
+		}
+		if u.Suppress != nil {
+			l.suppress = *u.Suppress
+		}
+		if u.SamplerTick != nil {
+			l.samplerTick = tick
+		}
+		if u.LogFirst != nil {
+			l.logFirst = *u.LogFirst
+		}
+		if u.ThenEvery != nil {
+			l.thenEvery = *u.ThenEvery
+		}
+		if u.CallerFilters != nil {
+			l.callerFilters = *u.CallerFilters
+		}
+	})
+	return nil
+}
+
+// levelName returns the name of level as used by Settings.
+func levelName(level int8) string {
+	if level == Warning {
+		return "warning"
+	}
+	return zapcore.Level(level).String()
+}
+
+// parseLevel returns the level with the given name. Both "warn" and
+// "warning" are accepted for Warning.
+func parseLevel(name string) (int8, error) {
+	if strings.EqualFold(name, "warning") {
+		return Warning, nil
+	}
+	lvl, err := zapcore.ParseLevel(strings.ToLower(name))
+	if err != nil {
+		return 0, err
+	}
+	switch int8(lvl) {
+	case Debug, Info, Warning, Error, Fatal:
+		return int8(lvl), nil
+	}
+	return 0, fmt.Errorf("unsupported level: %q", name)
+}
@@ -0,0 +1,105 @@
+/*
+DESCRIPTION
+  handler_test.go provides testing for functionality found in handler.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestHandler tests reading and changing logger settings over HTTP.
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Info, &buf, true)
+	h := NewHandler(l)
+
+	do := func(method, body string) (int, Settings) {
+		req := httptest.NewRequest(method, "/logging", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var s Settings
+		if rec.Code == http.StatusOK {
+			err := json.Unmarshal(rec.Body.Bytes(), &s)
+			if err != nil {
+				t.Fatalf("could not unmarshal response: %v", err)
+			}
+		}
+		return rec.Code, s
+	}
+
+	code, got := do(http.MethodGet, "")
+	want := Settings{
+		Level:         "info",
+		Suppress:      true,
+		SamplerTick:   "1m0s",
+		LogFirst:      defaultLogFirst,
+		ThenEvery:     defaultThenEvery,
+		CallerFilters: []string{},
+	}
+	if code != http.StatusOK || !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected GET response: code: %d settings: %+v want: %+v", code, got, want)
+	}
+
+	code, got = do(http.MethodPut, `{"level":"debug","suppress":false,"samplerTick":"10s","callerFilters":["foo.go"]}`)
+	want.Level = "debug"
+	want.Suppress = false
+	want.SamplerTick = "10s"
+	want.CallerFilters = []string{"foo.go"}
+	if code != http.StatusOK || !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected PUT response: code: %d settings: %+v want: %+v", code, got, want)
+	}
+
+	// The new level should apply to logging.
+	l.Debug("now visible")
+	if !strings.Contains(buf.String(), "now visible") {
+		t.Errorf("expected debug entry after changing level, got:\n%s", buf.String())
+	}
+
+	// Invalid updates should be rejected without applying any part of them.
+	for _, body := range []string{
+		`{"level":"loud"}`,
+		`{"level":"error","samplerTick":"-1s"}`,
+		`{"unknown":true}`,
+		`not json`,
+	} {
+		code, _ = do(http.MethodPut, body)
+		if code != http.StatusBadRequest {
+			t.Errorf("expected bad request for %s, got: %d", body, code)
+		}
+	}
+	if _, got = do(http.MethodGet, ""); got.Level != "debug" {
+		t.Errorf("level changed by invalid update, got: %s", got.Level)
+	}
+
+	code, _ = do(http.MethodPost, "{}")
+	if code != http.StatusMethodNotAllowed {
+		t.Errorf("expected method not allowed for POST, got: %d", code)
+	}
+}
@@ -0,0 +1,170 @@
+/*
+DESCRIPTION
+  journald.go provides a Sink that writes to the systemd journal using the
+  journald native protocol, mapping log levels to priorities and key:value
+  pairs to journal fields.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultJournalSocket is the path of the journald native protocol socket.
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// Journal is a Sink writing to the systemd journal. Each key:value pair is
+// written as a journal field with the key converted to upper case and any
+// characters not permitted in field names replaced by underscores. Keys
+// that would name a field the Journal writes itself, such as MESSAGE or
+// PRIORITY, are prefixed with F_, as are keys starting with a digit.
+//
+// Entries must fit in a single datagram; entries larger than the socket
+// allows are not written and an error is returned to zap.
+type Journal struct {
+	identifier string
+	level      zap.AtomicLevel
+	conn       *net.UnixConn
+}
+
+// NewJournal returns a Journal sink writing entries at or above level to
+// the journald socket at addr, or DefaultJournalSocket if addr is empty.
+// The identifier is used as the SYSLOG_IDENTIFIER of each entry.
+func NewJournal(addr, identifier string, level int8) (*Journal, error) {
+	if addr == "" {
+		addr = DefaultJournalSocket
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{
+		identifier: identifier,
+		level:      zap.NewAtomicLevelAt(zapcore.Level(level)),
+		conn:       conn,
+	}, nil
+}
+
+// Core implements Sink.
+func (j *Journal) Core() zapcore.Core {
+	return &entryCore{LevelEnabler: j.level, write: j.write}
+}
+
+// SetLevel sets the minimum level of entries written to the journal.
+func (j *Journal) SetLevel(level int8) {
+	j.level.SetLevel(zapcore.Level(level))
+}
+
+// Close closes the connection to the journal.
+func (j *Journal) Close() error {
+	return j.conn.Close()
+}
+
+// write sends e to the journal.
+func (j *Journal) write(e Entry) error {
+	var b bytes.Buffer
+	journalField(&b, "MESSAGE", e.Message)
+	journalField(&b, "PRIORITY", strconv.Itoa(severity(e.Level)))
+	if j.identifier != "" {
+		journalField(&b, "SYSLOG_IDENTIFIER", j.identifier)
+	}
+	if i := strings.LastIndex(e.Caller, ":"); i > 0 {
+		journalField(&b, "CODE_FILE", e.Caller[:i])
+		journalField(&b, "CODE_LINE", e.Caller[i+1:])
+	}
+	if e.Stack != "" {
+		journalField(&b, "STACK_TRACE", e.Stack)
+	}
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := journalName(k)
+		if name == "" {
+			continue
+		}
+		journalField(&b, name, fmt.Sprint(e.Fields[k]))
+	}
+
+	_, err := j.conn.Write(b.Bytes())
+	return err
+}
+
+// journalField writes a field to b using the native protocol. Values
+// containing newlines are written in the binary length-prefixed form.
+func journalField(b *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(name + "=" + value + "\n")
+		return
+	}
+	b.WriteString(name + "\n")
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value + "\n")
+}
+
+// journalFields are the names of the fields written by Journal itself.
+var journalFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"STACK_TRACE":       true,
+}
+
+// journalName returns k as a valid journal field name, i.e. at most 64
+// upper case letters, digits and underscores, not starting with an
+// underscore or digit, that does not clash with the fields written by
+// Journal. An empty string is returned if there is no valid name.
+func journalName(k string) string {
+	k = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, k)
+	k = strings.TrimLeft(k, "_")
+	if k != "" && (k[0] >= '0' && k[0] <= '9' || journalFields[k]) {
+		k = "F_" + k
+	}
+	if len(k) > 64 {
+		k = k[:64]
+	}
+	return k
+}
@@ -0,0 +1,72 @@
+/*
+DESCRIPTION
+  journald_test.go provides testing for functionality found in journald.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestJournal tests that entries are written using the journald native
+// protocol with the expected priority and fields.
+func TestJournal(t *testing.T) {
+	conn, path := listenUnixgram(t)
+	j, err := NewJournal(path, "test", Debug)
+	if err != nil {
+		t.Fatalf("could not create journal sink: %v", err)
+	}
+	defer j.Close()
+
+	logger := NewTee(j)
+	logger.Error("first line\nsecond line", "sensor-id", 3, "_private", true)
+
+	got := readDatagram(t, conn)
+	for _, want := range []string{
+		"MESSAGE\n\x16\x00\x00\x00\x00\x00\x00\x00first line\nsecond line\n",
+		"PRIORITY=3\n",
+		"SYSLOG_IDENTIFIER=test\n",
+		"CODE_FILE=logging/journald_test.go\n",
+		"SENSOR_ID=3\n",
+		"PRIVATE=true\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected journal entry to contain %q, got:\n%q", want, got)
+		}
+	}
+
+	// Keys naming fields written by the sink are prefixed.
+	logger.Info("msg", "message", "user", "priority", 9, "code_line", 1, "stack-trace", "x")
+	got = readDatagram(t, conn)
+	for _, want := range []string{"F_MESSAGE=user\n", "F_PRIORITY=9\n", "F_CODE_LINE=1\n", "F_STACK_TRACE=x\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected journal entry to contain %q, got:\n%q", want, got)
+		}
+	}
+	for _, name := range []string{"MESSAGE", "PRIORITY", "CODE_LINE"} {
+		if n := strings.Count("\n"+got, "\n"+name+"="); n != 1 {
+			t.Errorf("got %d %s fields, want 1:\n%q", n, name, got)
+		}
+	}
+}
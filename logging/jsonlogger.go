@@ -2,7 +2,8 @@
 DESCRIPTION
   jsonlogger.go provides an implementation of the Logger interface providing
   JSON styled logs with message and key-value pairs for additional information.
-  This logger also provides log suppression for repeated messages.
+  This logger also provides log suppression for repeated messages, reporting
+  how many times each message was suppressed at the end of each sampler tick.
 
 AUTHOR
   Jack Richardson <richardson.jack@outlook.com>
@@ -28,10 +29,12 @@ LICENSE
 package logging
 
 import (
+	"fmt"
 	"io"
 	"math"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -40,8 +43,9 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// Caller skip i.e. at what level the caller is logged as.
-const callerSkip = 2
+// Caller skip i.e. at what level the caller is logged as. Frames skipped are
+// write, the logger's log method and its exported logging method.
+const callerSkip = 3
 
 // Sampler defaults.
 const (
@@ -59,6 +63,12 @@ const (
 	defaultStackTraceKey = "stackTrace"
 )
 
+// Keys of the fields attached to suppression summary entries.
+const (
+	suppressedMessageKey = "suppressedMessage"
+	suppressedCountKey   = "suppressedCount"
+)
+
 type JSONLogger struct {
 	*zap.SugaredLogger
 	level         zap.AtomicLevel
@@ -70,12 +80,42 @@ type JSONLogger struct {
 	suppress      bool      // Indicates state of suppression on repetitive logging.
 	callerFilters []string  // Filters to apply to caller.
 	config        zapcore.EncoderConfig
+	customConfig  bool         // Indicates config was provided by the user and should not be altered.
+	format        Format       // The output format, i.e. JSON or console.
+	devMode       bool         // Indicates malformed key:value pairs are reported.
+	fatalHook     FatalHook    // What to do after logging a Fatal entry.
+	core          zapcore.Core // The core used by the logger, including any sampling.
+	base          zapcore.Core // The core without sampling, used to write suppression summaries.
 	mu            sync.Mutex
+
+	// Suppression accounting, guarded by smu.
+	pending   map[suppressionKey]uint64 // Entries suppressed since the last summary.
+	scheduled bool                      // Indicates a summary has been scheduled.
+	stats     SuppressionStats          // Entries suppressed since creation or the last reset.
+	smu       sync.Mutex
+}
+
+// suppressionKey identifies a suppressed message. The sampler treats
+// messages of different levels independently so we do the same.
+type suppressionKey struct {
+	level   zapcore.Level
+	message string
+}
+
+// SuppressionStats holds counts of log entries dropped by the sampler.
+type SuppressionStats struct {
+	Total    uint64            // Total number of suppressed entries.
+	Messages map[string]uint64 // Number of suppressed entries for each message.
 }
 
 // New generates and returns a new JSONLogger.
 // Optionally, a zapcore encoder config can be passed, otherwise a default is used.
 func New(verbosity int8, writer io.Writer, suppress bool, config ...zapcore.EncoderConfig) *JSONLogger {
+	return newLogger(JSONFormat, verbosity, writer, suppress, config...)
+}
+
+// newLogger returns a new JSONLogger writing in the given format.
+func newLogger(format Format, verbosity int8, writer io.Writer, suppress bool, config ...zapcore.EncoderConfig) *JSONLogger {
 	// set up a default zapcore encoder configuration.
 	cfg := zapcore.EncoderConfig{
 		MessageKey:    defaultMessageKey,
@@ -89,20 +129,25 @@ func New(verbosity int8, writer io.Writer, suppress bool, config ...zapcore.Enco
 	}
 
 	// If an optional encoder config is provided we overwrite the default above.
-	if len(config) != 0 {
+	custom := len(config) != 0
+	if custom {
 		cfg = config[0]
 	}
 
 	// Populate logger fields that will then be used for initialisation.
 	l := &JSONLogger{
-		level:       zap.NewAtomicLevel(),
-		samplerTick: defaultSamplerTick,
-		logFirst:    defaultLogFirst,
-		thenEvery:   defaultThenEvery,
-		verbosity:   verbosity,
-		writer:      writer,
-		suppress:    suppress,
-		config:      cfg,
+		level:        zap.NewAtomicLevel(),
+		samplerTick:  defaultSamplerTick,
+		logFirst:     defaultLogFirst,
+		thenEvery:    defaultThenEvery,
+		verbosity:    verbosity,
+		writer:       writer,
+		suppress:     suppress,
+		config:       cfg,
+		customConfig: custom,
+		format:       format,
+		pending:      make(map[suppressionKey]uint64),
+		stats:        SuppressionStats{Messages: make(map[string]uint64)},
 	}
 	l.init()
 	return l
@@ -120,108 +165,278 @@ func (l *JSONLogger) Log(level int8, msg string, args ...interface{}) { l.log(le
 // appropriate Zap call. Logs may be filtered based on the caller file name if SetCallerFilters
 // is used.
 func (l *JSONLogger) log(level int8, message string, args ...interface{}) {
-	if !l.shouldLog() {
+	// Return early for disabled levels, but always run the fatal hook.
+	if !l.level.Enabled(zapcore.Level(level)) && level != Fatal {
 		return
 	}
 
 	// Lock so that we synchronise with any re-initialisation.
 	l.mu.Lock()
+	if !shouldLog(l.callerFilters) {
+		l.mu.Unlock()
+		return
+	}
+	write(l.SugaredLogger, l.devMode, level, message, args...)
+	hook := l.fatalHook
+	l.mu.Unlock()
+
+	if level == Fatal {
+		hook.run(message)
+	}
+}
+
+// write logs the message and key:value pairs to s using the Zap call
+// appropriate for level. Warnings and above are synced immediately. If the
+// key:value pairs are malformed and devMode is true, an Error entry
+// describing the problem is logged first, with the same caller.
+func write(s *zap.SugaredLogger, devMode bool, level int8, message string, args ...interface{}) {
+	// Avoid the cost of normalising the arguments of disabled entries.
+	if !s.Level().Enabled(zapcore.Level(level)) {
+		return
+	}
+
+	fields, err := normalise(args)
+	if err != nil && devMode {
+		s.Errorw(malformedMessage, "error", err.Error(), malformedMessageKey, message)
+	}
+
+	kvs := interfaces(fields)
 	switch level {
 	case Fatal:
-		l.Fatalw(message, args...)
+		s.Fatalw(message, kvs...)
 	case Error:
-		l.Errorw(message, args...)
+		s.Errorw(message, kvs...)
 	case Warning:
-		l.Warnw(message, args...)
+		s.Warnw(message, kvs...)
 	case Info:
-		l.Infow(message, args...)
+		s.Infow(message, kvs...)
 	case Debug:
-		l.Debugw(message, args...)
+		s.Debugw(message, kvs...)
 	}
 
-	if level >= Warning {
-		l.Sync()
+	if level >= Warning || (err != nil && devMode) {
+		s.Sync()
 	}
-	l.mu.Unlock()
 }
 
 // shouldLog returns true if the caller should be logged, and false otherwise
-// based on whether the log caller file is in the callerFilters.
-func (l *JSONLogger) shouldLog() bool {
+// based on whether the log caller file is in the callerFilters. It must be
+// called directly from a logger's log method.
+func shouldLog(callerFilters []string) bool {
 	// Get the file name where the log was called from.
 	const skip = 3
 	_, file, _, ok := runtime.Caller(skip)
 	if !ok {
 		return true
 	}
+	return !filtered(callerFilters, file)
+}
+
+// filtered returns true if the caller file matches any of the callerFilters.
+func filtered(callerFilters []string, file string) bool {
 	// Make sure we have the base.
 	file = filepath.Base(file)
-	for _, f := range l.callerFilters {
+	for _, f := range callerFilters {
 		if strings.Contains(file, f) {
-			return false
+			return true
 		}
 	}
-	return true
+	return false
 }
 
 // SetSamplerTick sets the global samplerTick that will apply for all loggers.
 func (l *JSONLogger) SetSamplerTick(d time.Duration) {
-	l.samplerTick = d
-	l.init()
+	l.update(func() { l.samplerTick = d })
 }
 
 // SetLogFirst sets the global logFirst count that will apply for all loggers.
 func (l *JSONLogger) SetLogFirst(n int) {
-	l.logFirst = n
-	l.init()
+	l.update(func() { l.logFirst = n })
 }
 
 // SetThenEvery sets the global thenEvery count that will apply for all loggers.
 func (l *JSONLogger) SetThenEvery(n int) {
-	l.thenEvery = n
-	l.init()
+	l.update(func() { l.thenEvery = n })
 }
 
 // SetLevel sets the maximum log level that will be written to file
 func (l *JSONLogger) SetLevel(level int8) {
+	l.mu.Lock()
+	l.verbosity = level
 	l.level.SetLevel(zapcore.Level(level))
+	l.mu.Unlock()
 }
 
 // SetSuppress will turn on log sampling if s is true, and false otherwise.
 func (l *JSONLogger) SetSuppress(s bool) {
-	l.suppress = s
-	l.init()
+	l.update(func() { l.suppress = s })
+}
+
+// SetFormat sets the output format of the logger. Entries logged after the
+// call are written in the new format.
+func (l *JSONLogger) SetFormat(f Format) {
+	l.update(func() { l.format = f })
+}
+
+// SetDevMode turns on development mode if on is true, and off otherwise. In
+// development mode, logging with a malformed list of key:value pairs, e.g. a
+// key without a value or a value without a key, also logs an Error entry
+// describing the problem with the caller of the offending call. Malformed
+// pairs are always logged in the same way as log/slog, using "!BADKEY" as
+// the key of any value lacking one.
+func (l *JSONLogger) SetDevMode(on bool) {
+	l.mu.Lock()
+	l.devMode = on
+	l.mu.Unlock()
+}
+
+// SetFatalHook sets the behaviour of the logger after writing a Fatal entry.
+// By default the program exits immediately.
+func (l *JSONLogger) SetFatalHook(h FatalHook) {
+	l.mu.Lock()
+	l.fatalHook = h
+	l.mu.Unlock()
 }
 
 // SetCallerFilters will set the caller filters.
 // Therefore, if a caller file is in the callerFilters, it will not be logged.
 func (l *JSONLogger) SetCallerFilters(filters ...string) {
+	l.mu.Lock()
 	l.callerFilters = filters
+	l.mu.Unlock()
 }
 
 // init will initialise the logger with a zap logger containing a core, which
 // may also possess a sampler.
 func (l *JSONLogger) init() {
+	l.update(func() {})
+}
+
+// update applies fn to the logger's settings and re-initialises the logger.
+func (l *JSONLogger) update(fn func()) {
 	// Lock so that we synchronise with any logging currently happening.
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	fn()
+	l.build()
+}
 
+// build creates the logger's cores and zap logger from its settings.
+// It must be called with l.mu held.
+func (l *JSONLogger) build() {
+	// The writer is locked as the logger's core may also be used by a
+	// TeeLogger, which does not hold l.mu while writing.
 	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(l.config),
-		zapcore.AddSync(l.writer),
+		l.encoder(),
+		zapcore.Lock(zapcore.AddSync(l.writer)),
 		l.level,
 	)
 
+	l.base = core
+
 	// If we're suppressing repetitive logs, we add a sampling layer to the core.
 	if l.suppress {
-		core = zapcore.NewSampler(core, l.samplerTick, l.logFirst, l.thenEvery)
+		tick := l.samplerTick
+		core = zapcore.NewSamplerWithOptions(
+			core,
+			tick,
+			l.logFirst,
+			l.thenEvery,
+			zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+				l.sampled(ent, dec, tick)
+			}),
+		)
 	}
+	l.core = core
 
 	l.SugaredLogger = zap.New(core).WithOptions(
 		zap.AddCaller(),
 		zap.AddCallerSkip(callerSkip),
 		zap.AddStacktrace(zap.ErrorLevel),
+		zap.WithFatalHook(deferFatal{}),
 	).Sugar()
 
-	l.SetLevel(l.verbosity)
+	l.level.SetLevel(zapcore.Level(l.verbosity))
+}
+
+// encoder returns an encoder for the logger's format. Unless the user
+// provided their own encoder config, console output has coloured levels.
+func (l *JSONLogger) encoder() zapcore.Encoder {
+	if l.format != ConsoleFormat {
+		return zapcore.NewJSONEncoder(l.config)
+	}
+	cfg := l.config
+	if !l.customConfig {
+		cfg.EncodeLevel = colourLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(cfg)
+}
+
+// SuppressionStats returns the number of log entries that have been suppressed
+// since the logger was created or ResetSuppressionStats was last called.
+func (l *JSONLogger) SuppressionStats() SuppressionStats {
+	l.smu.Lock()
+	defer l.smu.Unlock()
+	stats := SuppressionStats{Total: l.stats.Total, Messages: make(map[string]uint64, len(l.stats.Messages))}
+	for msg, n := range l.stats.Messages {
+		stats.Messages[msg] = n
+	}
+	return stats
+}
+
+// ResetSuppressionStats zeroes the suppression counters.
+func (l *JSONLogger) ResetSuppressionStats() {
+	l.smu.Lock()
+	l.stats = SuppressionStats{Messages: make(map[string]uint64)}
+	l.smu.Unlock()
+}
+
+// sampled is called by the sampler for every entry it sees. Dropped entries
+// are counted and, on the first drop of a tick, a summary is scheduled for
+// the end of the tick.
+func (l *JSONLogger) sampled(ent zapcore.Entry, dec zapcore.SamplingDecision, tick time.Duration) {
+	if dec&zapcore.LogDropped == 0 {
+		return
+	}
+	l.smu.Lock()
+	defer l.smu.Unlock()
+	l.pending[suppressionKey{ent.Level, ent.Message}]++
+	l.stats.Messages[ent.Message]++
+	l.stats.Total++
+	if !l.scheduled {
+		l.scheduled = true
+		time.AfterFunc(tick, func() { l.summarise(tick) })
+	}
+}
+
+// summarise writes an entry for each message suppressed since the last
+// summary stating how many times it was suppressed. Summaries bypass the
+// sampler so that they are never themselves suppressed.
+func (l *JSONLogger) summarise(tick time.Duration) {
+	l.smu.Lock()
+	pending := l.pending
+	l.pending = make(map[suppressionKey]uint64)
+	l.scheduled = false
+	l.smu.Unlock()
+
+	keys := make([]suppressionKey, 0, len(pending))
+	for k := range pending {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].message < keys[j].message })
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, k := range keys {
+		n := pending[k]
+		ent := zapcore.Entry{
+			Level:   k.level,
+			Time:    time.Now(),
+			Message: fmt.Sprintf("message %q suppressed %d times in the last %v", k.message, n, tick),
+		}
+		if ce := l.base.Check(ent, nil); ce != nil {
+			ce.Write(zap.String(suppressedMessageKey, k.message), zap.Uint64(suppressedCountKey, n))
+		}
+	}
+	l.base.Sync()
 }
@@ -35,6 +35,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -53,14 +54,13 @@ func printMessages() {
 }
 
 func TestFatal(t *testing.T) {
-	t.Skip("Won't work with network logging")
 	if os.Getenv("BE_CRASHER") == "1" {
-		//logger = New(Info, "../")
+		logger = New(Info, os.Stderr, false)
 		logger.Log(Info, "Testing Fatal Logging")
 		logger.Log(Fatal, "dying")
 		return
 	}
-	cmd := exec.Command(os.Args[0], "-test.run=TestFatal")
+	cmd := exec.Command(os.Args[0], "-test.run=TestFatal$")
 	cmd.Env = append(os.Environ(), "BE_CRASHER=1")
 	err := cmd.Run()
 	if e, ok := err.(*exec.ExitError); ok && !e.Success() {
@@ -69,6 +69,49 @@ func TestFatal(t *testing.T) {
 	t.Fatalf("process ran with err %v, want exit status 1", err)
 }
 
+// TestFatalHook tests each of the fatal actions, and that the cleanup function
+// is run, and may log, before the action is taken.
+func TestFatalHook(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Info, &buf, false)
+
+	var cleaned bool
+	l.SetFatalHook(FatalHook{
+		Action:  FatalReturn,
+		Cleanup: func() { cleaned = true; l.Info("cleaning up") },
+	})
+	l.Fatal("fatal return")
+	if !cleaned {
+		t.Error("expected cleanup to be called")
+	}
+	if !strings.Contains(buf.String(), "fatal return") || !strings.Contains(buf.String(), "cleaning up") {
+		t.Errorf("expected fatal entry and cleanup entry, got:\n%s", buf.String())
+	}
+
+	l.SetFatalHook(FatalHook{Action: FatalPanic})
+	func() {
+		defer func() {
+			if p := recover(); p != "fatal panic" {
+				t.Errorf("unexpected panic value: %v", p)
+			}
+		}()
+		l.Fatal("fatal panic")
+	}()
+
+	var exitCode int
+	exit = func(code int) { exitCode = code }
+	defer func() { exit = os.Exit }()
+	start := time.Now()
+	l.SetFatalHook(FatalHook{Cleanup: func() { time.Sleep(time.Minute) }, Timeout: 10 * time.Millisecond})
+	l.Fatal("fatal exit")
+	if exitCode != 1 {
+		t.Errorf("unexpected exit code: got: %d want: 1", exitCode)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("cleanup timeout was not applied")
+	}
+}
+
 func TestBasicLogging(t *testing.T) {
 	t.Skip("Won't work with network logging")
 
@@ -143,6 +186,82 @@ func TestSampler(t *testing.T) {
 	}
 }
 
+// TestSuppressionSummary tests that suppressed messages are counted and that
+// a summary of the suppressed messages is written at the end of the tick.
+func TestSuppressionSummary(t *testing.T) {
+	const (
+		tick   = 200 * time.Millisecond
+		nLogs  = 10
+		msg    = "repeated message"
+		wantSS = `"suppressedMessage":"repeated message","suppressedCount":9`
+	)
+
+	var buf syncBuffer
+	l := New(Debug, &buf, true)
+	l.SetSamplerTick(tick)
+	l.SetLogFirst(1)
+	for i := 0; i < nLogs; i++ {
+		l.Info(msg)
+	}
+
+	stats := l.SuppressionStats()
+	if stats.Total != nLogs-1 || stats.Messages[msg] != nLogs-1 {
+		t.Errorf("unexpected suppression stats: got:%+v want total and count of %d", stats, nLogs-1)
+	}
+
+	time.Sleep(3 * tick)
+	if !strings.Contains(buf.String(), wantSS) {
+		t.Errorf("did not get expected summary %q in output:\n%s", wantSS, buf.String())
+	}
+
+	l.ResetSuppressionStats()
+	if stats := l.SuppressionStats(); stats.Total != 0 || len(stats.Messages) != 0 {
+		t.Errorf("unexpected suppression stats after reset: %+v", stats)
+	}
+}
+
+// TestFormat tests that a console logger writes human-readable output and
+// that the format can be switched on a live logger.
+func TestFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewConsole(Debug, &buf, false)
+	l.Warning("console message", "key", "value")
+	got := buf.String()
+	for _, want := range []string{"WARN", "jsonlogger_test.go", "console message", `{"key": "value"}`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("console output does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.HasPrefix(got, "{") {
+		t.Errorf("did not expect JSON output from console logger:\n%s", got)
+	}
+
+	buf.Reset()
+	l.SetFormat(JSONFormat)
+	l.Warning("json message")
+	if want := `{"level":"warn"`; !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected output to start with %q after switching format, got:\n%s", want, buf.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 // TestCallerFilter tests that we can apply log caller filters
 // using JSONLogger.SetCallerFilters and that the filters are applied.
 func TestCallerFilter(t *testing.T) {
@@ -185,6 +304,7 @@ func TestCallerSkip(t *testing.T) {
 		buf    bytes.Buffer
 	)
 	logger = New(Debug, &buf, false)
+	logger.(*JSONLogger).SetFatalHook(FatalHook{Action: FatalReturn})
 
 	// Test that each log function has the expected caller.
 	for _, f := range []func(string, ...interface{}){
@@ -192,7 +312,7 @@ func TestCallerSkip(t *testing.T) {
 		logger.Info,
 		logger.Warning,
 		logger.Error,
-		// Can't test logger.Fatal as it calls os.Exit.
+		logger.Fatal,
 	} {
 		f("test")
 		if !strings.Contains(buf.String(), file) {
@@ -212,3 +332,23 @@ func TestCallerSkip(t *testing.T) {
 func functionName(i interface{}) string {
 	return runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name()
 }
+
+// TestDisabledAllocs checks that entries below the level are discarded
+// without normalising their key:value pairs.
+func TestDisabledAllocs(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Info, &buf, false)
+	tee := NewTee(New(Error, &buf, false))
+	for _, logger := range []Logger{l, tee} {
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.Debug("disabled", "key", "value", "other", 42)
+		})
+		// The only allocation is of the variadic arguments.
+		if allocs > 1 {
+			t.Errorf("%T: got %v allocations for disabled entry, want at most 1", logger, allocs)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
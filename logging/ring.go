@@ -0,0 +1,174 @@
+/*
+DESCRIPTION
+  ring.go provides a Sink that keeps the most recent log entries, at all
+  levels, in memory so that they may be queried or dumped after a failure.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Ring is a Sink holding the last N log entries in memory regardless of the
+// levels of the other sinks of a TeeLogger. For example, to keep the last
+// 5000 entries and dump them if the program panics or logs a Fatal entry:
+//
+//	ring := logging.NewRing(5000)
+//	ring.DumpOnFatal(os.Stderr)
+//	defer ring.DumpOnPanic(os.Stderr)
+//	log := logging.NewTee(logging.New(logging.Info, f, true), ring)
+type Ring struct {
+	entries []Entry
+	next    int       // Index of the next entry to write.
+	full    bool      // Indicates entries has wrapped.
+	dump    io.Writer // Where to dump entries on Fatal, if non-nil.
+	mu      sync.Mutex
+}
+
+// NewRing returns a new Ring holding up to n entries.
+func NewRing(n int) *Ring {
+	if n <= 0 {
+		panic("logging: non-positive ring size")
+	}
+	return &Ring{entries: make([]Entry, n)}
+}
+
+// Core implements Sink. Entries of all levels are kept.
+func (r *Ring) Core() zapcore.Core {
+	return &entryCore{LevelEnabler: zapcore.DebugLevel, write: r.add}
+}
+
+// add adds e to the ring, overwriting the oldest entry if the ring is full.
+// If e is a Fatal entry and DumpOnFatal has been called, the ring is dumped.
+func (r *Ring) add(e Entry) error {
+	r.mu.Lock()
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	dump := r.dump
+	r.mu.Unlock()
+
+	if e.Level >= Fatal && dump != nil {
+		return r.Dump(dump)
+	}
+	return nil
+}
+
+// Filter reports whether an entry should be included in the result of Query.
+type Filter func(Entry) bool
+
+// MinLevel returns a Filter accepting entries at or above level.
+func MinLevel(level int8) Filter {
+	return func(e Entry) bool { return e.Level >= level }
+}
+
+// Between returns a Filter accepting entries logged in the interval [from, to).
+// A zero from or to leaves the interval unbounded at that end.
+func Between(from, to time.Time) Filter {
+	return func(e Entry) bool {
+		return (from.IsZero() || !e.Time.Before(from)) && (to.IsZero() || e.Time.Before(to))
+	}
+}
+
+// HasField returns a Filter accepting entries with the given key. If value is
+// non-nil, the field must also have the same value when formatted with %v.
+func HasField(key string, value interface{}) Filter {
+	return func(e Entry) bool {
+		v, ok := e.Fields[key]
+		if !ok {
+			return false
+		}
+		return value == nil || fmt.Sprint(v) == fmt.Sprint(value)
+	}
+}
+
+// Entries returns the entries held by the ring, oldest first.
+func (r *Ring) Entries() []Entry {
+	return r.Query()
+}
+
+// Query returns the entries, oldest first, accepted by all of the filters.
+func (r *Ring) Query(filters ...Filter) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ordered []Entry
+	if r.full {
+		ordered = append(ordered, r.entries[r.next:]...)
+	}
+	ordered = append(ordered, r.entries[:r.next]...)
+
+	result := ordered[:0]
+next:
+	for _, e := range ordered {
+		for _, f := range filters {
+			if !f(e) {
+				continue next
+			}
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// Dump writes the entries held by the ring to w, oldest first, as one JSON
+// object per line.
+func (r *Ring) Dump(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, e := range r.Entries() {
+		err := enc.Encode(e)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DumpOnFatal causes the ring to be dumped to w when a Fatal entry is logged,
+// before the logger carries out its fatal behaviour. A nil w disables dumping.
+func (r *Ring) DumpOnFatal(w io.Writer) {
+	r.mu.Lock()
+	r.dump = w
+	r.mu.Unlock()
+}
+
+// DumpOnPanic dumps the ring to w if the calling goroutine is panicking, and
+// then continues panicking. It must be deferred directly, i.e.
+//
+//	defer ring.DumpOnPanic(os.Stderr)
+func (r *Ring) DumpOnPanic(w io.Writer) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	r.Dump(w)
+	panic(p)
+}
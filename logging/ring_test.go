@@ -0,0 +1,108 @@
+/*
+DESCRIPTION
+  ring_test.go provides testing for functionality found in ring.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRing tests that a Ring keeps the most recent entries at all levels,
+// and that they can be queried and dumped.
+func TestRing(t *testing.T) {
+	var buf bytes.Buffer
+	ring := NewRing(3)
+	logger := NewTee(New(Error, &buf, false), ring)
+
+	start := time.Now()
+	logger.Debug("first")
+	logger.Debug("second", "n", 2)
+	logger.Info("third", "n", 3)
+	logger.Warning("fourth", "n", 4)
+
+	if strings.Contains(buf.String(), "second") {
+		t.Errorf("did not expect debug entry in error sink, got:\n%s", buf.String())
+	}
+
+	tests := []struct {
+		name    string
+		filters []Filter
+		want    []string
+	}{
+		{name: "all", want: []string{"second", "third", "fourth"}},
+		{name: "level", filters: []Filter{MinLevel(Info)}, want: []string{"third", "fourth"}},
+		{name: "field", filters: []Filter{HasField("n", 3)}, want: []string{"third"}},
+		{name: "field present", filters: []Filter{HasField("n", nil), MinLevel(Warning)}, want: []string{"fourth"}},
+		{name: "time", filters: []Filter{Between(time.Time{}, start)}, want: nil},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, e := range ring.Query(test.filters...) {
+			got = append(got, e.Message)
+		}
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("%s: unexpected entries, got: %v want: %v", test.name, got, test.want)
+		}
+	}
+
+	if e := ring.Entries()[0]; !strings.Contains(e.Caller, "ring_test.go") {
+		t.Errorf("unexpected caller %q", e.Caller)
+	}
+
+	var dump bytes.Buffer
+	err := ring.Dump(&dump)
+	if err != nil {
+		t.Fatalf("unexpected error from Dump: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(dump.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[2], `"level":"warn","message":"fourth"`) {
+		t.Errorf("unexpected dump:\n%s", dump.String())
+	}
+}
+
+// TestRingDumpOnPanic tests that the ring is dumped when a panic passes
+// through DumpOnPanic, and that the panic continues.
+func TestRingDumpOnPanic(t *testing.T) {
+	ring := NewRing(10)
+	logger := NewTee(ring)
+	var dump bytes.Buffer
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic to continue after dump")
+			}
+		}()
+		defer ring.DumpOnPanic(&dump)
+		logger.Debug("before panic")
+		panic("test")
+	}()
+
+	if !strings.Contains(dump.String(), "before panic") {
+		t.Errorf("expected dump to contain entry, got:\n%s", dump.String())
+	}
+}
@@ -0,0 +1,230 @@
+/*
+DESCRIPTION
+  slog.go provides a bridge between this package and the standard library's
+  log/slog package, i.e. an slog.Handler that writes to a JSONLogger and a
+  Logger that writes to an slog.Logger.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogLevelFatal is the slog level corresponding to Fatal. The slog package
+// does not define a fatal level, so we use the next level above error.
+const slogLevelFatal = slog.LevelError + 4
+
+// slogHandler implements slog.Handler, writing records to a JSONLogger.
+type slogHandler struct {
+	l      *JSONLogger
+	fields []zapcore.Field // Fields added by WithAttrs.
+	prefix string          // Key prefix from groups added by WithGroup.
+}
+
+// NewSlogHandler returns an slog.Handler that writes records to l. Records are
+// subject to l's level, caller filters and suppression, and the caller is
+// taken from the record's source position. Attributes within groups are
+// logged with keys qualified by the group names, e.g. "group.key".
+func NewSlogHandler(l *JSONLogger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// Enabled implements slog.Handler.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.level.Enabled(fromSlogLevel(level))
+}
+
+// Handle implements slog.Handler.
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	ent := zapcore.Entry{
+		Level:   fromSlogLevel(r.Level),
+		Time:    r.Time,
+		Message: r.Message,
+	}
+	if ent.Time.IsZero() {
+		ent.Time = time.Now()
+	}
+	if r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ent.Caller = zapcore.NewEntryCaller(f.PC, f.File, f.Line, true)
+		ent.Caller.Function = f.Function
+	}
+
+	fields := append([]zapcore.Field{}, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+
+	h.l.mu.Lock()
+	defer h.l.mu.Unlock()
+	if ent.Caller.Defined && filtered(h.l.callerFilters, ent.Caller.File) {
+		return nil
+	}
+	if ce := h.l.core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := append([]zapcore.Field{}, h.fields...)
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.prefix, a)
+	}
+	return &slogHandler{l: h.l, fields: fields, prefix: h.prefix}
+}
+
+// WithGroup implements slog.Handler.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{l: h.l, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// appendAttr appends the fields for a to fields, qualifying keys with prefix.
+// Groups are flattened and empty attributes are ignored.
+func appendAttr(fields []zapcore.Field, prefix string, a slog.Attr) []zapcore.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+	return append(fields, zap.Any(prefix+a.Key, a.Value.Any()))
+}
+
+// fromSlogLevel returns the zapcore level for an slog level.
+func fromSlogLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slogLevelFatal:
+		return zapcore.FatalLevel
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+// toSlogLevel returns the slog level for a Logger level.
+func toSlogLevel(level int8) slog.Level {
+	switch level {
+	case Fatal:
+		return slogLevelFatal
+	case Error:
+		return slog.LevelError
+	case Warning:
+		return slog.LevelWarn
+	case Info:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// SlogLogger provides an implementation of Logger that writes to an
+// slog.Logger. The caller of each logging method is recorded as the source
+// of the slog record.
+type SlogLogger struct {
+	s         *slog.Logger
+	level     slog.LevelVar
+	fatalHook FatalHook
+	mu        sync.Mutex
+}
+
+// NewSlogLogger returns a new SlogLogger writing to s. Its level is initially
+// Debug so that records are filtered only by the slog handler.
+func NewSlogLogger(s *slog.Logger) *SlogLogger {
+	l := &SlogLogger{s: s}
+	l.level.Set(slog.LevelDebug)
+	return l
+}
+
+// Logging methods for each level.
+func (l *SlogLogger) Debug(msg string, args ...interface{})           { l.log(Debug, msg, args...) }
+func (l *SlogLogger) Info(msg string, args ...interface{})            { l.log(Info, msg, args...) }
+func (l *SlogLogger) Warning(msg string, args ...interface{})         { l.log(Warning, msg, args...) }
+func (l *SlogLogger) Error(msg string, args ...interface{})           { l.log(Error, msg, args...) }
+func (l *SlogLogger) Fatal(msg string, args ...interface{})           { l.log(Fatal, msg, args...) }
+func (l *SlogLogger) Log(level int8, msg string, args ...interface{}) { l.log(level, msg, args...) }
+
+// SetLevel sets the minimum level of records passed to the slog.Logger.
+func (l *SlogLogger) SetLevel(level int8) {
+	l.level.Set(toSlogLevel(level))
+}
+
+// SetFatalHook sets the behaviour of the logger after writing a Fatal entry.
+// By default the program exits immediately.
+func (l *SlogLogger) SetFatalHook(h FatalHook) {
+	l.mu.Lock()
+	l.fatalHook = h
+	l.mu.Unlock()
+}
+
+// log creates a record for the message and key:value pairs and passes it to
+// the slog.Logger's handler. Fields are converted to attributes and malformed
+// pairs are handled as for the other loggers. A Fatal entry is followed by the
+// logger's FatalHook.
+func (l *SlogLogger) log(level int8, message string, args ...interface{}) {
+	lvl := toSlogLevel(level)
+	ctx := context.Background()
+	if lvl >= l.level.Level() && l.s.Enabled(ctx, lvl) {
+		// Skip runtime.Callers, log and the exported logging method.
+		var pcs [1]uintptr
+		runtime.Callers(3, pcs[:])
+		r := slog.NewRecord(time.Now(), lvl, message, pcs[0])
+		fields, _ := normalise(args)
+		for _, f := range fields {
+			if v, ok := fieldValue(f); ok {
+				r.AddAttrs(slog.Any(f.Key, v))
+			}
+		}
+		l.s.Handler().Handle(ctx, r)
+	}
+	if level == Fatal {
+		l.mu.Lock()
+		hook := l.fatalHook
+		l.mu.Unlock()
+		hook.run(message)
+	}
+}
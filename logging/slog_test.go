@@ -0,0 +1,82 @@
+/*
+DESCRIPTION
+  slog_test.go provides testing for functionality found in slog.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestSlogHandler tests that records logged through an slog.Logger backed by
+// a JSONLogger honour the logger's level and caller filters.
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Info, &buf, false)
+	s := slog.New(NewSlogHandler(l)).With("component", "test").WithGroup("g")
+
+	s.Debug("hidden")
+	s.Warn("visible", "n", 1, slog.Group("sub", "m", 2))
+	got := buf.String()
+	if strings.Contains(got, "hidden") {
+		t.Errorf("did not expect debug record in output:\n%s", got)
+	}
+	for _, want := range []string{`"level":"warn"`, `"caller":"logging/slog_test.go`, `"message":"visible"`, `"component":"test"`, `"g.n":1`, `"g.sub.m":2`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %s, got:\n%s", want, got)
+		}
+	}
+
+	buf.Reset()
+	l.SetCallerFilters("slog_test.go")
+	s.Error("filtered")
+	if buf.Len() != 0 {
+		t.Errorf("did not expect filtered record in output:\n%s", buf.String())
+	}
+}
+
+// TestSlogLogger tests that a SlogLogger writes to its slog.Logger with the
+// correct level, attributes and source.
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug})
+	var logger Logger = NewSlogLogger(slog.New(h))
+
+	logger.Warning("warning message", "key", "value")
+	got := buf.String()
+	for _, want := range []string{`"level":"WARN"`, `"msg":"warning message"`, `"key":"value"`, `slog_test.go"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %s, got:\n%s", want, got)
+		}
+	}
+
+	buf.Reset()
+	logger.SetLevel(Error)
+	logger.Info("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("did not expect output below level, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,263 @@
+/*
+DESCRIPTION
+  syslog.go provides a Sink that writes RFC 5424 formatted messages to a
+  syslog socket, mapping log levels to syslog severities and key:value pairs
+  to structured data.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Syslog facilities, as defined by RFC 5424.
+const (
+	FacilityUser   = 1
+	FacilityDaemon = 3
+	FacilityLocal0 = 16
+)
+
+// sdID is the structured data ID used for key:value pairs. 32473 is the
+// private enterprise number reserved for documentation by RFC 5612.
+const sdID = "fields@32473"
+
+// Local syslog socket paths, in order of preference.
+var syslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Syslog is a Sink writing to a syslog daemon.
+type Syslog struct {
+	network  string
+	addr     string
+	tag      string
+	hostname string
+	facility int
+	level    zap.AtomicLevel
+	conn     net.Conn
+	stream   bool // Indicates conn is a stream, rather than datagram, connection.
+	mu       sync.Mutex
+}
+
+// NewSyslog returns a Syslog sink writing entries at or above level. If
+// network is empty, the local syslog socket is used, otherwise network and
+// addr are as for net.Dial. The tag identifies the program in each message,
+// and the facility is initially FacilityUser.
+func NewSyslog(network, addr, tag string, level int8) (*Syslog, error) {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	s := &Syslog{
+		network:  network,
+		addr:     addr,
+		tag:      tag,
+		hostname: hostname,
+		facility: FacilityUser,
+		level:    zap.NewAtomicLevelAt(zapcore.Level(level)),
+	}
+	err = s.connect()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// connect dials the syslog daemon. It must be called with s.mu held or
+// before s is shared.
+func (s *Syslog) connect() error {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	if s.network != "" {
+		conn, err := net.Dial(s.network, s.addr)
+		if err != nil {
+			return err
+		}
+		s.conn, s.stream = conn, isStream(s.network)
+		return nil
+	}
+	for _, path := range syslogPaths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				s.conn, s.stream = conn, isStream(network)
+				return nil
+			}
+		}
+	}
+	return errors.New("could not connect to local syslog")
+}
+
+// Core implements Sink.
+func (s *Syslog) Core() zapcore.Core {
+	return &entryCore{LevelEnabler: s.level, write: s.write}
+}
+
+// SetLevel sets the minimum level of entries written to syslog.
+func (s *Syslog) SetLevel(level int8) {
+	s.level.SetLevel(zapcore.Level(level))
+}
+
+// SetFacility sets the syslog facility of subsequent messages.
+func (s *Syslog) SetFacility(facility int) {
+	s.mu.Lock()
+	s.facility = facility
+	s.mu.Unlock()
+}
+
+// Close closes the connection to the syslog daemon.
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// write sends e to the syslog daemon, reconnecting once if the write fails.
+func (s *Syslog) write(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		_, err := s.conn.Write(s.format(e))
+		if err == nil {
+			return nil
+		}
+	}
+	err := s.connect()
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Write(s.format(e))
+	return err
+}
+
+// format returns e as an RFC 5424 message. Messages sent over stream
+// connections are newline terminated.
+func (s *Syslog) format(e Entry) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d - ",
+		s.facility*8+severity(e.Level),
+		e.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname,
+		headerField(s.tag, 48),
+		os.Getpid(),
+	)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if e.Caller == "" && len(keys) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + sdID)
+		if e.Caller != "" {
+			b.WriteString(` caller="` + sdEscape(e.Caller) + `"`)
+		}
+		for _, k := range keys {
+			fmt.Fprintf(&b, ` %s="%s"`, sdName(k), sdEscape(fmt.Sprint(e.Fields[k])))
+		}
+		b.WriteString("]")
+	}
+
+	b.WriteString(" " + e.Message)
+	if s.stream {
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// isStream returns true if network is a stream network for net.Dial.
+func isStream(network string) bool {
+	return network != "unixgram" && !strings.HasPrefix(network, "udp")
+}
+
+// severity returns the syslog severity for a level.
+func severity(level int8) int {
+	switch level {
+	case Fatal:
+		return 2 // Critical.
+	case Error:
+		return 3
+	case Warning:
+		return 4
+	case Info:
+		return 6
+	default:
+		return 7 // Debug.
+	}
+}
+
+// headerField returns s limited to n printable ASCII characters, or "-" if
+// s is empty.
+func headerField(s string, n int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > n {
+		s = s[:n]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// sdName returns k as a valid structured data parameter name.
+func sdName(k string) string {
+	k = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, k)
+	if len(k) > 32 {
+		k = k[:32]
+	}
+	if k == "" {
+		return "_"
+	}
+	return k
+}
+
+// sdEscape escapes the characters that must be escaped in structured data
+// parameter values.
+var sdEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace
@@ -0,0 +1,85 @@
+/*
+DESCRIPTION
+  syslog_test.go provides testing for functionality found in syslog.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// listenUnixgram returns a datagram socket standing in for a system logging
+// daemon. A short temporary directory is used as socket paths are limited in
+// length.
+func listenUnixgram(t *testing.T) (*net.UnixConn, string) {
+	dir, err := os.MkdirTemp("", "log")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("could not listen on unix socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// readDatagram reads a single datagram from conn.
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("could not read from socket: %v", err)
+	}
+	return string(buf[:n])
+}
+
+// TestSyslog tests that entries are written as RFC 5424 messages with the
+// expected priority and structured data.
+func TestSyslog(t *testing.T) {
+	conn, path := listenUnixgram(t)
+	s, err := NewSyslog("unixgram", path, "test", Info)
+	if err != nil {
+		t.Fatalf("could not create syslog sink: %v", err)
+	}
+	defer s.Close()
+	s.SetFacility(FacilityDaemon)
+
+	logger := NewTee(s)
+	logger.Debug("hidden")
+	logger.Warning("disk nearly full", "path", "/var/log", "note", `a "quoted" value]`)
+
+	got := readDatagram(t, conn)
+	want := regexp.MustCompile(`^<28>1 \S+ \S+ test \d+ - \[fields@32473 caller="logging/syslog_test.go:\d+" note="a \\"quoted\\" value\\]" path="/var/log"\] disk nearly full$`)
+	if !want.MatchString(got) {
+		t.Errorf("unexpected syslog message:\n%s", got)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+DESCRIPTION
+  tee.go provides an implementation of the Logger interface that fans out
+  log entries to multiple sinks, each with its own level, encoding and
+  suppression settings.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt. If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Sink is a destination for log entries that may be combined with other
+// sinks using a TeeLogger.
+type Sink interface {
+	// Core returns a zapcore.Core that writes entries to the sink.
+	Core() zapcore.Core
+}
+
+// TeeLogger provides an implementation of Logger that writes each entry to
+// all of its sinks. Each sink decides for itself whether an entry is written,
+// so a JSONLogger used as a sink keeps its own level, format and suppression
+// settings. For example, the following logs debug to a file, info to stdout
+// and errors to a remote endpoint:
+//
+//	file := logging.New(logging.Debug, f, false)
+//	console := logging.NewConsole(logging.Info, os.Stdout, true)
+//	remote := logging.New(logging.Error, conn, true)
+//	log := logging.NewTee(file, console, remote)
+type TeeLogger struct {
+	*zap.SugaredLogger
+	level         zap.AtomicLevel // The minimum level passed on to any sink.
+	callerFilters []string        // Filters to apply to caller.
+	devMode       bool            // Indicates malformed key:value pairs are reported.
+	fatalHook     FatalHook       // What to do after logging a Fatal entry.
+	mu            sync.Mutex
+}
+
+// NewTee returns a new TeeLogger writing to the given sinks. The level of the
+// TeeLogger is initially Debug so that entries are filtered only by the sinks.
+func NewTee(sinks ...Sink) *TeeLogger {
+	cores := make([]zapcore.Core, len(sinks))
+	for i, s := range sinks {
+		cores[i] = s.Core()
+	}
+	return &TeeLogger{
+		SugaredLogger: zap.New(zapcore.NewTee(cores...)).WithOptions(
+			zap.AddCaller(),
+			zap.AddCallerSkip(callerSkip),
+			zap.AddStacktrace(zap.ErrorLevel),
+			zap.WithFatalHook(deferFatal{}),
+		).Sugar(),
+		level: zap.NewAtomicLevelAt(zapcore.Level(Debug)),
+	}
+}
+
+// Logging methods for each level.
+func (t *TeeLogger) Debug(msg string, args ...interface{})           { t.log(Debug, msg, args...) }
+func (t *TeeLogger) Info(msg string, args ...interface{})            { t.log(Info, msg, args...) }
+func (t *TeeLogger) Warning(msg string, args ...interface{})         { t.log(Warning, msg, args...) }
+func (t *TeeLogger) Error(msg string, args ...interface{})           { t.log(Error, msg, args...) }
+func (t *TeeLogger) Fatal(msg string, args ...interface{})           { t.log(Fatal, msg, args...) }
+func (t *TeeLogger) Log(level int8, msg string, args ...interface{}) { t.log(level, msg, args...) }
+
+// log writes the message and key:value pairs to each of the sinks, unless
+// level is below the TeeLogger's level or the caller is filtered.
+func (t *TeeLogger) log(level int8, message string, args ...interface{}) {
+	// Return early if no sink would write the entry, but always run the
+	// fatal hook.
+	enabled := t.level.Enabled(zapcore.Level(level)) && t.SugaredLogger.Level().Enabled(zapcore.Level(level))
+	if !enabled && level != Fatal {
+		return
+	}
+	t.mu.Lock()
+	filters, devMode, hook := t.callerFilters, t.devMode, t.fatalHook
+	t.mu.Unlock()
+	if !shouldLog(filters) {
+		return
+	}
+	write(t.SugaredLogger, devMode, level, message, args...)
+	if level == Fatal {
+		hook.run(message)
+	}
+}
+
+// SetLevel sets the minimum level of entries passed on to the sinks. Sinks
+// may further filter entries according to their own levels.
+func (t *TeeLogger) SetLevel(level int8) {
+	t.level.SetLevel(zapcore.Level(level))
+}
+
+// SetCallerFilters will set the caller filters.
+// Therefore, if a caller file is in the callerFilters, it will not be logged.
+func (t *TeeLogger) SetCallerFilters(filters ...string) {
+	t.mu.Lock()
+	t.callerFilters = filters
+	t.mu.Unlock()
+}
+
+// SetDevMode turns development mode on or off. See JSONLogger.SetDevMode.
+func (t *TeeLogger) SetDevMode(on bool) {
+	t.mu.Lock()
+	t.devMode = on
+	t.mu.Unlock()
+}
+
+// SetFatalHook sets the behaviour of the logger after writing a Fatal entry.
+// By default the program exits immediately.
+func (t *TeeLogger) SetFatalHook(h FatalHook) {
+	t.mu.Lock()
+	t.fatalHook = h
+	t.mu.Unlock()
+}
+
+// Core returns a zapcore.Core that writes to the logger, allowing the
+// JSONLogger to be used as a Sink. The core follows later changes to the
+// logger's level, format and suppression settings.
+func (l *JSONLogger) Core() zapcore.Core {
+	return &loggerCore{l: l}
+}
+
+// loggerCore implements zapcore.Core by delegating to the current core of a
+// JSONLogger, which is replaced whenever the logger is re-initialised.
+type loggerCore struct {
+	l      *JSONLogger
+	fields []zapcore.Field
+}
+
+func (c *loggerCore) current() zapcore.Core {
+	c.l.mu.Lock()
+	core := c.l.core
+	c.l.mu.Unlock()
+	if len(c.fields) != 0 {
+		core = core.With(c.fields)
+	}
+	return core
+}
+
+func (c *loggerCore) Enabled(lvl zapcore.Level) bool { return c.l.level.Enabled(lvl) }
+
+func (c *loggerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &loggerCore{l: c.l, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c *loggerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	return c.current().Check(ent, ce)
+}
+
+func (c *loggerCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.current().Write(ent, fields)
+}
+
+func (c *loggerCore) Sync() error { return c.current().Sync() }
@@ -0,0 +1,89 @@
+/*
+DESCRIPTION
+  tee_test.go provides testing for functionality found in tee.go.
+
+AUTHOR
+  agent <agent@local>
+
+LICENSE
+  Copyright (C) 2026 the Australian Ocean Lab (AusOcean).
+
+  It is free software: you can redistribute it and/or modify them
+  under the terms of the GNU General Public License as published by the
+  Free Software Foundation, either version 3 of the License, or (at your
+  option) any later version.
+
+  It is distributed in the hope that it will be useful, but WITHOUT
+  ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+  FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License
+  for more details.
+
+  You should have received a copy of the GNU General Public License
+  in gpl.txt.  If not, see [GNU licenses](http://www.gnu.org/licenses).
+*/
+
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestTee tests that a TeeLogger writes to each sink according to the sink's
+// own level and format, and that the caller is reported correctly.
+func TestTee(t *testing.T) {
+	var debug, info, errs bytes.Buffer
+	debugSink := New(Debug, &debug, false)
+	infoSink := NewConsole(Info, &info, false)
+	errorSink := New(Error, &errs, false)
+
+	var logger Logger = NewTee(debugSink, infoSink, errorSink)
+
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Error("error message")
+
+	tests := []struct {
+		name string
+		buf  *bytes.Buffer
+		want []string
+		not  []string
+	}{
+		{name: "debug", buf: &debug, want: []string{"debug message", "info message", "error message"}},
+		{name: "info", buf: &info, want: []string{"info message", "error message"}, not: []string{"debug message"}},
+		{name: "error", buf: &errs, want: []string{"error message"}, not: []string{"debug message", "info message"}},
+	}
+	for _, test := range tests {
+		got := test.buf.String()
+		for _, w := range test.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s sink: expected output to contain %q, got:\n%s", test.name, w, got)
+			}
+		}
+		for _, n := range test.not {
+			if strings.Contains(got, n) {
+				t.Errorf("%s sink: did not expect output to contain %q, got:\n%s", test.name, n, got)
+			}
+		}
+		if !strings.Contains(got, "tee_test.go") {
+			t.Errorf("%s sink: expected caller to be tee_test.go, got:\n%s", test.name, got)
+		}
+	}
+
+	// Changing the level of a sink after creating the tee should take effect.
+	debug.Reset()
+	debugSink.SetLevel(Error)
+	logger.Info("ignored message")
+	if debug.Len() != 0 {
+		t.Errorf("did not expect output after raising sink level, got:\n%s", debug.String())
+	}
+
+	// The level of the tee applies to all sinks.
+	info.Reset()
+	logger.SetLevel(Error)
+	logger.Warning("ignored message")
+	if info.Len() != 0 {
+		t.Errorf("did not expect output after raising tee level, got:\n%s", info.String())
+	}
+}
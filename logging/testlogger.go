@@ -26,7 +26,10 @@ LICENSE
 
 package logging
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 // TestLogger provides an implementation of Logger. It uses the testing.T
 // struct for logging, i.e. this logger is useful for code requiring an
@@ -62,16 +65,23 @@ func (dl *TestLogger) Log(lvl int8, msg string, args ...interface{}) {
 		return
 	}
 
-	// Add braces with args inside to message.
+	// Add braces with args inside to message. Args are normalised in the same
+	// way as for the JSONLogger, and any problem with them is reported.
+	fields, err := normalise(args)
+	if err != nil {
+		dl.Logf("malformed log parameters: %v", err)
+	}
 	msg += " ("
-	for i := 0; i < len(args); i += 2 {
-		msg += " %v:\"%v\""
+	for _, f := range fields {
+		if v, ok := fieldValue(f); ok {
+			msg += fmt.Sprintf(" %v:\"%v\"", f.Key, v)
+		}
 	}
 	msg += " )"
 
 	if lvl == Fatal {
-		dl.Fatalf(msg+"\n", args...)
+		((*testing.T)(dl)).Fatal(msg)
 	}
 
-	dl.Logf(msg+"\n", args...)
+	((*testing.T)(dl)).Log(msg)
 }